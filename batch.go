@@ -0,0 +1,31 @@
+package MyLog
+
+// 批量信息输出，msgs中每个元素为一条日志，整批通过一次通道操作提交并按顺序输出
+func (l *Logger) InfoBatch(msgs []interface{}) {
+	l.handleLogMsg(INFO, batchMsg(msgs))
+}
+
+// 批量警告信息输出，msgs中每个元素为一条日志，整批通过一次通道操作提交并按顺序输出
+func (l *Logger) WarningBatch(msgs []interface{}) {
+	l.handleLogMsg(WARNING, batchMsg(msgs))
+}
+
+// 批量错误信息输出，msgs中每个元素为一条日志，整批通过一次通道操作提交并按顺序输出
+func (l *Logger) ErrorBatch(msgs []interface{}) {
+	l.handleLogMsg(ERROR, batchMsg(msgs))
+}
+
+// 同Logger.InfoBatch，作用于默认日志对象
+func InfoBatch(msgs []interface{}) {
+	logger.handleLogMsg(INFO, batchMsg(msgs))
+}
+
+// 同Logger.WarningBatch，作用于默认日志对象
+func WarningBatch(msgs []interface{}) {
+	logger.handleLogMsg(WARNING, batchMsg(msgs))
+}
+
+// 同Logger.ErrorBatch，作用于默认日志对象
+func ErrorBatch(msgs []interface{}) {
+	logger.handleLogMsg(ERROR, batchMsg(msgs))
+}
@@ -0,0 +1,110 @@
+package MyLog
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestBatchOrderAndCount(t *testing.T) {
+	l, buf := newTestLogger(t, WithFlags(FLAG_NONE))
+	l.Info("before")
+	l.InfoBatch([]interface{}{"a", 2, fmt.Errorf("c")})
+	l.WarningBatch(nil)
+	l.Info("after")
+	l.Flush()
+	// 批量消息按顺序输出在前后的日志之间，空批次不输出
+	if got := buf.String(); got != "before\na\n2\nc\nafter\n" {
+		t.Fatalf("got %q", got)
+	}
+	if got := l.LevelCount(INFO); got != 5 {
+		t.Fatalf("LevelCount(INFO) = %d, want 5", got)
+	}
+	if got := l.LevelCount(WARNING); got != 0 {
+		t.Fatalf("LevelCount(WARNING) = %d, want 0", got)
+	}
+}
+
+func TestDroppedBatchCountsEveryItem(t *testing.T) {
+	for _, policy := range []OverflowPolicy{OVERFLOW_DROP, OVERFLOW_DROP_OLDEST} {
+		l, w := floodLogger(t, policy)
+		// 输出协程阻塞在第一条上，通道容量为2
+		l.InfoBatch([]interface{}{"a", "b", "c"})
+		l.Info("x")
+		l.Info("y")
+		// DROP丢弃新的y，DROP_OLDEST丢弃最旧的整批，均按实际条数计入
+		want, output := uint64(1), "1\na\nb\nc\nx\n"
+		if policy == OVERFLOW_DROP_OLDEST {
+			want, output = 3, "1\nx\ny\n"
+		}
+		if got := l.DroppedCount(); got != want {
+			t.Errorf("policy %d: dropped %d, want %d", policy, got, want)
+		}
+		// 整批被丢弃时各条同样计入
+		if policy == OVERFLOW_DROP {
+			l.InfoBatch([]interface{}{"d", "e"})
+			if got := l.DroppedCount(); got != 3 {
+				t.Errorf("policy %d: dropped %d after full batch, want 3", policy, got)
+			}
+		}
+		close(w.release)
+		l.Flush()
+		if got := w.buf.String(); got != output {
+			t.Errorf("policy %d: got %q, want %q", policy, got, output)
+		}
+	}
+}
+
+func TestDiscardMsgReleasesBatch(t *testing.T) {
+	items := []*logMsg{{msg: "a"}, {msg: "b"}}
+	log := &logMsg{batch: items}
+	if n := discardMsg(log); n != 2 {
+		t.Fatalf("discardMsg = %d, want 2", n)
+	}
+	// 批次中的各条已清空放回池中
+	for _, m := range items {
+		if m.msg != "" {
+			t.Fatalf("batch item not released: %+v", *m)
+		}
+	}
+	if n := discardMsg(&logMsg{msg: "x"}); n != 1 {
+		t.Fatalf("discardMsg = %d, want 1", n)
+	}
+}
+
+// 共100行，逐行提交与批量提交的对比
+func BenchmarkPerLine(b *testing.B) {
+	l := New(WithOutputType(ONLY_TERMINAL), WithOutput(io.Discard), WithFlags(FLAG_LEVEL), WithOverflowPolicy(OVERFLOW_BLOCK))
+	defer l.Close()
+	msgs := batchOf(100)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, m := range msgs {
+			l.Info(m)
+		}
+	}
+	l.Flush()
+}
+
+func BenchmarkBatch(b *testing.B) {
+	l := New(WithOutputType(ONLY_TERMINAL), WithOutput(io.Discard), WithFlags(FLAG_LEVEL), WithOverflowPolicy(OVERFLOW_BLOCK))
+	defer l.Close()
+	msgs := batchOf(100)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		l.InfoBatch(msgs)
+	}
+	l.Flush()
+}
+
+// 生成n条消息
+func batchOf(n int) []interface{} {
+	msgs := make([]interface{}, n)
+	for i := range msgs {
+		msgs[i] = strings.Repeat("x", 40)
+	}
+	return msgs
+}
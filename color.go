@@ -0,0 +1,51 @@
+package MyLog
+
+import (
+	"io"
+	"os"
+)
+
+// 各日志等级在终端中的颜色
+var levelColor = map[LevelLog]string{
+	TRACE:   "\x1b[90m", // 灰色
+	DEBUG:   "\x1b[90m", // 灰色
+	INFO:    "\x1b[32m", // 绿色
+	WARNING: "\x1b[33m", // 黄色
+	ERROR:   "\x1b[31m", // 红色
+	FATAL:   "\x1b[31m", // 红色
+}
+
+const colorReset = "\x1b[0m"
+
+// 强制设置终端输出是否按日志等级着色，文件输出始终不着色
+// 未调用时按输出目标自动判断：只有输出到真实终端时着色，重定向到文件、管道或其他io.Writer时不着色
+func (l *Logger) SetColor(enable bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.color = enable
+	l.errColor = enable
+	l.colorForced = true
+}
+
+// 同Logger.SetColor，作用于默认日志对象
+func SetColor(enable bool) {
+	logger.SetColor(enable)
+}
+
+// 为日志等级标识添加颜色
+func colorize(level LevelLog, label string) string {
+	return levelColor[level] + label + colorReset
+}
+
+// 判断输出目标是否为终端，重定向到文件或管道时返回false
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,89 @@
+package MyLog
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestColorTerminalOnly(t *testing.T) {
+	dir := t.TempDir()
+	var term bytes.Buffer
+	l := New(WithOutputType(BOTH_TERMINAL_AND_FILE), WithOutput(&term), WithErrorToStderr(false),
+		WithFile(filepath.Join(dir, "c.log")), WithFlags(FLAG_LEVEL), WithColor(true))
+	defer l.Close()
+	l.Info("i")
+	l.Error("e")
+	l.Flush()
+	want := "[\x1b[32mINFO   \x1b[0m] i\n[\x1b[31mERROR  \x1b[0m] e\n"
+	if term.String() != want {
+		t.Fatalf("terminal = %q", term.String())
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "c.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "\x1b[") {
+		t.Fatalf("file has ANSI codes: %q", data)
+	}
+}
+
+func TestColorOffForNonTerminal(t *testing.T) {
+	l, buf := newTestLogger(t)
+	l.Warning("plain")
+	l.Flush()
+	if strings.Contains(buf.String(), "\x1b[") {
+		t.Fatalf("buffer output colored: %q", buf.String())
+	}
+	if isTerminal(&bytes.Buffer{}) {
+		t.Fatal("bytes.Buffer detected as terminal")
+	}
+}
+
+func TestColorDetectionForFilesAndPipes(t *testing.T) {
+	f, err := os.Create(filepath.Join(t.TempDir(), "out"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+	// 重定向到文件或管道时不是终端
+	if isTerminal(f) || isTerminal(w) {
+		t.Fatal("file or pipe detected as terminal")
+	}
+
+	l, _ := newTestLogger(t, WithOutput(f))
+	l.Info("plain")
+	l.Flush()
+	data, _ := os.ReadFile(f.Name())
+	if strings.Contains(string(data), "\x1b[") {
+		t.Fatalf("redirected output colored: %q", data)
+	}
+}
+
+func TestSetColorForces(t *testing.T) {
+	l, buf := newTestLogger(t)
+	l.SetColor(true)
+	// 强制开启后更换输出目标也保持开启
+	var other bytes.Buffer
+	l.SetOutput(&other)
+	l.Info("on")
+	l.Flush()
+	if got := other.String(); got != "[\x1b[32mINFO   \x1b[0m] on\n" {
+		t.Fatalf("forced on got %q", got)
+	}
+	l.SetOutput(buf)
+	l.SetColor(false)
+	l.Info("off")
+	l.Flush()
+	if got := buf.String(); got != "[INFO   ] off\n" {
+		t.Fatalf("forced off got %q", got)
+	}
+}
@@ -0,0 +1,77 @@
+package MyLog
+
+import (
+	"context"
+)
+
+// context中存储字段的key
+type fieldsCtxKey struct{}
+
+// 在context中添加字段，使用该context输出的日志会带上这些字段，同名字段后设置的生效
+func WithField(ctx context.Context, key string, value interface{}) context.Context {
+	old := ctxFields(ctx)
+	fields := make([]field, 0, len(old)+1)
+	for _, f := range old {
+		if f.key != key {
+			fields = append(fields, f)
+		}
+	}
+	fields = append(fields, field{key: key, value: value})
+	return context.WithValue(ctx, fieldsCtxKey{}, fields)
+}
+
+// 获取context中存储的字段
+func ctxFields(ctx context.Context) []field {
+	if ctx == nil {
+		return nil
+	}
+	fields, _ := ctx.Value(fieldsCtxKey{}).([]field)
+	return fields
+}
+
+// 以下带context的输出函数在通道已满需要等待时，若ctx结束则放弃该条日志并计入DroppedCount，不会一直阻塞
+// 严重错误信息必须输出，FatalCtx不受ctx影响
+
+// 带context字段的信息输出
+func (l *Logger) InfoCtx(ctx context.Context, args ...interface{}) {
+	l.handleLogMsgCtx(ctx, INFO, sprintln(args...), ctxFields(ctx)...)
+}
+
+// 带context字段的警告信息输出
+func (l *Logger) WarningCtx(ctx context.Context, args ...interface{}) {
+	l.handleLogMsgCtx(ctx, WARNING, sprintln(args...), ctxFields(ctx)...)
+}
+
+// 带context字段的错误信息输出
+func (l *Logger) ErrorCtx(ctx context.Context, args ...interface{}) {
+	l.handleLogMsgCtx(ctx, ERROR, argsMsg{text: sprintln(args...), args: args}, ctxFields(ctx)...)
+}
+
+// 带context字段的严重错误信息输出，输出完毕后退出进程
+func (l *Logger) FatalCtx(ctx context.Context, args ...interface{}) {
+	l.handleLogMsg(FATAL, argsMsg{text: sprintln(args...), args: args}, ctxFields(ctx)...)
+	l.flush()
+	exit(1)
+}
+
+// 带context字段的信息输出
+func InfoCtx(ctx context.Context, args ...interface{}) {
+	logger.handleLogMsgCtx(ctx, INFO, sprintln(args...), ctxFields(ctx)...)
+}
+
+// 带context字段的警告信息输出
+func WarningCtx(ctx context.Context, args ...interface{}) {
+	logger.handleLogMsgCtx(ctx, WARNING, sprintln(args...), ctxFields(ctx)...)
+}
+
+// 带context字段的错误信息输出
+func ErrorCtx(ctx context.Context, args ...interface{}) {
+	logger.handleLogMsgCtx(ctx, ERROR, argsMsg{text: sprintln(args...), args: args}, ctxFields(ctx)...)
+}
+
+// 带context字段的严重错误信息输出，输出完毕后退出进程
+func FatalCtx(ctx context.Context, args ...interface{}) {
+	logger.handleLogMsg(FATAL, argsMsg{text: sprintln(args...), args: args}, ctxFields(ctx)...)
+	logger.flush()
+	exit(1)
+}
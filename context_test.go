@@ -0,0 +1,71 @@
+package MyLog
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestContextFields(t *testing.T) {
+	l, buf := newTestLogger(t)
+	ctx := WithField(context.Background(), "request_id", "abc123")
+	ctx = WithField(ctx, "user", 7)
+	l.InfoCtx(ctx, "handled")
+	l.Info("no context")
+	l.WarningCtx(context.Background(), "empty context")
+	l.InfoCtx(WithField(ctx, "user", 8), "overridden")
+	l.Flush()
+	want := []string{
+		"[INFO   ] request_id=abc123 user=7 handled",
+		"[INFO   ] no context",
+		"[WARNING] empty context",
+		"[INFO   ] request_id=abc123 user=8 overridden",
+	}
+	if got := lines(buf); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("got %q", got)
+	}
+}
+
+// 通道已满时，ctx已取消的调用立即返回并计入丢弃数，未取消的ctx在截止时间到达后放弃
+func TestCtxAbandonsEnqueueWhenFull(t *testing.T) {
+	l, w := floodLogger(t, OVERFLOW_BLOCK)
+	l.Info(2)
+	l.Info(3)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	start := time.Now()
+	l.InfoCtx(ctx, "cancelled")
+	if d := time.Since(start); d > time.Second {
+		t.Fatalf("InfoCtx with a cancelled context blocked for %v", d)
+	}
+	if got := l.DroppedCount(); got != 1 {
+		t.Fatalf("dropped %d, want 1", got)
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	l.WarningCtx(ctx, "timed out")
+	if got := l.DroppedCount(); got != 2 {
+		t.Fatalf("dropped %d, want 2", got)
+	}
+
+	close(w.release)
+	l.Flush()
+	if got := w.buf.String(); got != "1\n2\n3\n" {
+		t.Fatalf("got %q", got)
+	}
+}
+
+// 通道有空位时即使ctx已取消也正常输出
+func TestCtxCancelledWithRoom(t *testing.T) {
+	l, buf := newTestLogger(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	l.InfoCtx(ctx, "kept")
+	l.Flush()
+	if !strings.Contains(buf.String(), "kept") || l.DroppedCount() != 0 {
+		t.Fatalf("got %q, dropped %d", buf.String(), l.DroppedCount())
+	}
+}
@@ -0,0 +1,154 @@
+//go:build !mylog_nodebug
+
+package MyLog
+
+import (
+	"context"
+	"fmt"
+)
+
+// 默认构建下TRACE、DEBUG日志正常输出，使用-tags mylog_nodebug构建时见debug_stripped.go
+// 所有TRACE、DEBUG等级的输出函数都定义在此文件中，新增时需在debug_stripped.go中同时添加空实现
+
+// 为true时TRACE、DEBUG日志在编译时被去除
+const debugStripped = false
+
+// 跟踪信息输出，比调试信息更详细
+func (l *Logger) Trace(args ...interface{}) {
+	l.handleLogMsg(TRACE, sprintln(args...))
+}
+
+// 调试信息输出
+func (l *Logger) Debug(args ...interface{}) {
+	l.handleLogMsg(DEBUG, sprintln(args...))
+}
+
+// 格式化跟踪信息输出
+func (l *Logger) Tracef(format string, args ...interface{}) {
+	l.handleLogMsg(TRACE, fmt.Sprintf(format, args...))
+}
+
+// 格式化调试信息输出
+func (l *Logger) Debugf(format string, args ...interface{}) {
+	l.handleLogMsg(DEBUG, fmt.Sprintf(format, args...))
+}
+
+// 跟踪信息输出，比调试信息更详细
+func Trace(args ...interface{}) {
+	logger.handleLogMsg(TRACE, sprintln(args...))
+}
+
+// 调试信息输出
+func Debug(args ...interface{}) {
+	logger.handleLogMsg(DEBUG, sprintln(args...))
+}
+
+// 格式化跟踪信息输出
+func Tracef(format string, args ...interface{}) {
+	logger.handleLogMsg(TRACE, fmt.Sprintf(format, args...))
+}
+
+// 格式化调试信息输出
+func Debugf(format string, args ...interface{}) {
+	logger.handleLogMsg(DEBUG, fmt.Sprintf(format, args...))
+}
+
+// 按指定的输出字段跟踪信息输出，flags仅对本条消息生效
+func (l *Logger) TraceWithFlags(flags LogFlag, args ...interface{}) {
+	l.handleLogMsgFlags(TRACE, flags, sprintln(args...))
+}
+
+// 按指定的输出字段调试信息输出，flags仅对本条消息生效
+func (l *Logger) DebugWithFlags(flags LogFlag, args ...interface{}) {
+	l.handleLogMsgFlags(DEBUG, flags, sprintln(args...))
+}
+
+// 按指定的输出字段跟踪信息输出，flags仅对本条消息生效
+func TraceWithFlags(flags LogFlag, args ...interface{}) {
+	logger.handleLogMsgFlags(TRACE, flags, sprintln(args...))
+}
+
+// 按指定的输出字段调试信息输出，flags仅对本条消息生效
+func DebugWithFlags(flags LogFlag, args ...interface{}) {
+	logger.handleLogMsgFlags(DEBUG, flags, sprintln(args...))
+}
+
+// 延迟生成消息的跟踪信息输出，仅在该等级需要输出时才调用fn
+func (l *Logger) TraceFunc(fn func() string) {
+	l.handleLogMsg(TRACE, lazyMsg(fn))
+}
+
+// 延迟生成消息的调试信息输出，仅在该等级需要输出时才调用fn
+func (l *Logger) DebugFunc(fn func() string) {
+	l.handleLogMsg(DEBUG, lazyMsg(fn))
+}
+
+// 延迟生成消息的跟踪信息输出，仅在该等级需要输出时才调用fn
+func TraceFunc(fn func() string) {
+	logger.handleLogMsg(TRACE, lazyMsg(fn))
+}
+
+// 延迟生成消息的调试信息输出，仅在该等级需要输出时才调用fn
+func DebugFunc(fn func() string) {
+	logger.handleLogMsg(DEBUG, lazyMsg(fn))
+}
+
+// 批量跟踪信息输出，msgs中每个元素为一条日志，整批通过一次通道操作提交并按顺序输出
+func (l *Logger) TraceBatch(msgs []interface{}) {
+	l.handleLogMsg(TRACE, batchMsg(msgs))
+}
+
+// 批量调试信息输出，msgs中每个元素为一条日志，整批通过一次通道操作提交并按顺序输出
+func (l *Logger) DebugBatch(msgs []interface{}) {
+	l.handleLogMsg(DEBUG, batchMsg(msgs))
+}
+
+// 同Logger.TraceBatch，作用于默认日志对象
+func TraceBatch(msgs []interface{}) {
+	logger.handleLogMsg(TRACE, batchMsg(msgs))
+}
+
+// 同Logger.DebugBatch，作用于默认日志对象
+func DebugBatch(msgs []interface{}) {
+	logger.handleLogMsg(DEBUG, batchMsg(msgs))
+}
+
+// 带context字段的跟踪信息输出
+func (l *Logger) TraceCtx(ctx context.Context, args ...interface{}) {
+	l.handleLogMsgCtx(ctx, TRACE, sprintln(args...), ctxFields(ctx)...)
+}
+
+// 带context字段的调试信息输出
+func (l *Logger) DebugCtx(ctx context.Context, args ...interface{}) {
+	l.handleLogMsgCtx(ctx, DEBUG, sprintln(args...), ctxFields(ctx)...)
+}
+
+// 带context字段的跟踪信息输出
+func TraceCtx(ctx context.Context, args ...interface{}) {
+	logger.handleLogMsgCtx(ctx, TRACE, sprintln(args...), ctxFields(ctx)...)
+}
+
+// 带context字段的调试信息输出
+func DebugCtx(ctx context.Context, args ...interface{}) {
+	logger.handleLogMsgCtx(ctx, DEBUG, sprintln(args...), ctxFields(ctx)...)
+}
+
+// 跟踪信息输出
+func (e *Entry) Trace(args ...interface{}) {
+	e.logger.handleLogMsg(TRACE, sprintln(args...), e.fields...)
+}
+
+// 调试信息输出
+func (e *Entry) Debug(args ...interface{}) {
+	e.logger.handleLogMsg(DEBUG, sprintln(args...), e.fields...)
+}
+
+// 格式化跟踪信息输出
+func (e *Entry) Tracef(format string, args ...interface{}) {
+	e.logger.handleLogMsg(TRACE, fmt.Sprintf(format, args...), e.fields...)
+}
+
+// 格式化调试信息输出
+func (e *Entry) Debugf(format string, args ...interface{}) {
+	e.logger.handleLogMsg(DEBUG, fmt.Sprintf(format, args...), e.fields...)
+}
@@ -0,0 +1,98 @@
+//go:build mylog_nodebug
+
+package MyLog
+
+import (
+	"context"
+)
+
+// 使用-tags mylog_nodebug构建时TRACE、DEBUG的输出函数为空实现，调用会被内联为空操作
+// 注意参数表达式本身仍会被求值，开销较大的参数请配合IsEnabled判断或使用DebugFunc
+// slog、Writer等其他途径提交的TRACE、DEBUG日志同样被丢弃
+
+// 为true时TRACE、DEBUG日志在编译时被去除
+const debugStripped = true
+
+// 跟踪信息输出，比调试信息更详细，当前构建已去除
+func (l *Logger) Trace(args ...interface{}) {}
+
+// 调试信息输出，当前构建已去除
+func (l *Logger) Debug(args ...interface{}) {}
+
+// 格式化跟踪信息输出，当前构建已去除
+func (l *Logger) Tracef(format string, args ...interface{}) {}
+
+// 格式化调试信息输出，当前构建已去除
+func (l *Logger) Debugf(format string, args ...interface{}) {}
+
+// 跟踪信息输出，比调试信息更详细，当前构建已去除
+func Trace(args ...interface{}) {}
+
+// 调试信息输出，当前构建已去除
+func Debug(args ...interface{}) {}
+
+// 格式化跟踪信息输出，当前构建已去除
+func Tracef(format string, args ...interface{}) {}
+
+// 格式化调试信息输出，当前构建已去除
+func Debugf(format string, args ...interface{}) {}
+
+// 按指定的输出字段跟踪信息输出，flags仅对本条消息生效，当前构建已去除
+func (l *Logger) TraceWithFlags(flags LogFlag, args ...interface{}) {}
+
+// 按指定的输出字段调试信息输出，flags仅对本条消息生效，当前构建已去除
+func (l *Logger) DebugWithFlags(flags LogFlag, args ...interface{}) {}
+
+// 按指定的输出字段跟踪信息输出，flags仅对本条消息生效，当前构建已去除
+func TraceWithFlags(flags LogFlag, args ...interface{}) {}
+
+// 按指定的输出字段调试信息输出，flags仅对本条消息生效，当前构建已去除
+func DebugWithFlags(flags LogFlag, args ...interface{}) {}
+
+// 延迟生成消息的跟踪信息输出，仅在该等级需要输出时才调用fn，当前构建已去除
+func (l *Logger) TraceFunc(fn func() string) {}
+
+// 延迟生成消息的调试信息输出，仅在该等级需要输出时才调用fn，当前构建已去除
+func (l *Logger) DebugFunc(fn func() string) {}
+
+// 延迟生成消息的跟踪信息输出，仅在该等级需要输出时才调用fn，当前构建已去除
+func TraceFunc(fn func() string) {}
+
+// 延迟生成消息的调试信息输出，仅在该等级需要输出时才调用fn，当前构建已去除
+func DebugFunc(fn func() string) {}
+
+// 批量跟踪信息输出，msgs中每个元素为一条日志，整批通过一次通道操作提交并按顺序输出，当前构建已去除
+func (l *Logger) TraceBatch(msgs []interface{}) {}
+
+// 批量调试信息输出，msgs中每个元素为一条日志，整批通过一次通道操作提交并按顺序输出，当前构建已去除
+func (l *Logger) DebugBatch(msgs []interface{}) {}
+
+// 同Logger.TraceBatch，作用于默认日志对象
+func TraceBatch(msgs []interface{}) {}
+
+// 同Logger.DebugBatch，作用于默认日志对象
+func DebugBatch(msgs []interface{}) {}
+
+// 带context字段的跟踪信息输出，当前构建已去除
+func (l *Logger) TraceCtx(ctx context.Context, args ...interface{}) {}
+
+// 带context字段的调试信息输出，当前构建已去除
+func (l *Logger) DebugCtx(ctx context.Context, args ...interface{}) {}
+
+// 带context字段的跟踪信息输出，当前构建已去除
+func TraceCtx(ctx context.Context, args ...interface{}) {}
+
+// 带context字段的调试信息输出，当前构建已去除
+func DebugCtx(ctx context.Context, args ...interface{}) {}
+
+// 跟踪信息输出，当前构建已去除
+func (e *Entry) Trace(args ...interface{}) {}
+
+// 调试信息输出，当前构建已去除
+func (e *Entry) Debug(args ...interface{}) {}
+
+// 格式化跟踪信息输出，当前构建已去除
+func (e *Entry) Tracef(format string, args ...interface{}) {}
+
+// 格式化调试信息输出，当前构建已去除
+func (e *Entry) Debugf(format string, args ...interface{}) {}
@@ -0,0 +1,39 @@
+//go:build mylog_nodebug
+
+package MyLog
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+)
+
+func TestDebugStripped(t *testing.T) {
+	l, buf := newTestLogger(t)
+	l.SetLevel(TRACE)
+	called := false
+	fn := func() string { called = true; return "lazy" }
+	// 所有TRACE、DEBUG的输出途径都不输出
+	l.Trace("t")
+	l.Debugf("d %d", 1)
+	Debug("pkg")
+	l.DebugWithFlags(FLAG_ALL, "flags")
+	l.TraceFunc(fn)
+	l.DebugFunc(fn)
+	l.DebugBatch([]interface{}{"a", "b"})
+	l.DebugCtx(context.Background(), "ctx")
+	l.WithFields(Fields{"k": 1}).Debug("entry")
+	slog.New(l.NewSlogHandler()).Debug("slog")
+	l.Writer(DEBUG).Write([]byte("writer\n"))
+	l.Info("kept")
+	l.Flush()
+	if got := buf.String(); got != "[INFO   ] kept\n" {
+		t.Fatalf("got %q", got)
+	}
+	if called {
+		t.Fatal("lazy message built in a stripped build")
+	}
+	if l.IsEnabled(DEBUG) || !l.IsEnabled(INFO) {
+		t.Fatal("IsEnabled does not reflect the stripped levels")
+	}
+}
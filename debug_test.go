@@ -0,0 +1,43 @@
+//go:build !mylog_nodebug
+
+package MyLog
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestTraceLevel(t *testing.T) {
+	if !(TRACE < DEBUG && DEBUG < INFO) {
+		t.Fatal("TRACE must be the lowest level")
+	}
+	l, buf := newTestLogger(t)
+	l.Trace("hidden at DEBUG")
+	l.Tracef("hidden %d", 2)
+	l.Debug("debug")
+	l.SetLevel(TRACE)
+	l.Trace("shown")
+	l.Tracef("shown %d", 2)
+	l.Flush()
+	want := []string{"[DEBUG  ] debug", "[TRACE  ] shown", "[TRACE  ] shown 2"}
+	if got := lines(buf); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("got %q", got)
+	}
+}
+
+func TestDebugEntryPoints(t *testing.T) {
+	l, buf := newTestLogger(t)
+	l.SetLevel(TRACE)
+	// 默认构建下所有TRACE、DEBUG输出途径都正常输出
+	l.DebugWithFlags(FLAG_NONE, "flags")
+	l.TraceFunc(func() string { return "lazy" })
+	l.DebugBatch([]interface{}{"a", "b"})
+	l.DebugCtx(context.Background(), "ctx")
+	l.WithFields(Fields{"k": 1}).Tracef("entry %d", 2)
+	l.Flush()
+	want := []string{"flags", "[TRACE  ] lazy", "[DEBUG  ] a", "[DEBUG  ] b", "[DEBUG  ] ctx", "[TRACE  ] k=1 entry 2"}
+	if got := lines(buf); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,15 @@
+//go:build !windows
+
+package MyLog
+
+import (
+	"os"
+)
+
+// 默认的日志目录为当前工作目录，获取失败时使用系统临时目录
+func defaultLogDir() string {
+	if dir, err := os.Getwd(); err == nil {
+		return dir
+	}
+	return os.TempDir()
+}
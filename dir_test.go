@@ -0,0 +1,18 @@
+//go:build !windows
+
+package MyLog
+
+import (
+	"os"
+	"testing"
+)
+
+func TestDefaultLogDirIsWorkingDir(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := defaultLogDir(); got != wd {
+		t.Fatalf("defaultLogDir() = %q, want %q", got, wd)
+	}
+}
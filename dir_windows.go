@@ -0,0 +1,22 @@
+//go:build windows
+
+package MyLog
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Windows下程序常安装在不可写的目录中，默认的日志目录为%LocalAppData%\程序名\logs，未设置时使用%TEMP%
+func defaultLogDir() string {
+	base := os.Getenv("LOCALAPPDATA")
+	if base == "" {
+		return os.TempDir()
+	}
+	name := "MyLog"
+	if exe, err := os.Executable(); err == nil {
+		name = strings.TrimSuffix(filepath.Base(exe), filepath.Ext(exe))
+	}
+	return filepath.Join(base, name, "logs")
+}
@@ -0,0 +1,42 @@
+//go:build windows
+
+package MyLog
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDefaultLogDirLocalAppData(t *testing.T) {
+	base := t.TempDir()
+	t.Setenv("LOCALAPPDATA", base)
+	exe, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	name := strings.TrimSuffix(filepath.Base(exe), filepath.Ext(exe))
+	if got, want := defaultLogDir(), filepath.Join(base, name, "logs"); got != want {
+		t.Fatalf("defaultLogDir() = %q, want %q", got, want)
+	}
+
+	// 未设置LOCALAPPDATA时使用临时目录
+	t.Setenv("LOCALAPPDATA", "")
+	if got := defaultLogDir(); got != os.TempDir() {
+		t.Fatalf("defaultLogDir() = %q, want %q", got, os.TempDir())
+	}
+}
+
+func TestWindowsFileModeIgnored(t *testing.T) {
+	// Windows下0644等权限位只影响只读属性，打开和写入不应失败
+	l, path := newFileLogger(t, WithFlags(FLAG_NONE))
+	if err := l.SetFileMode(0600); err != nil {
+		t.Fatal(err)
+	}
+	l.Info("ok")
+	l.Flush()
+	if got := readFile(t, path); got != "ok\n" {
+		t.Fatalf("got %q", got)
+	}
+}
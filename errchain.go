@@ -0,0 +1,128 @@
+package MyLog
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+)
+
+// 设置ERROR、FATAL日志是否展开参数中的error，开启后附加三个字段：
+// error_chain记录逐层Unwrap得到的各层自身的消息（去掉内层错误的部分），error_types记录各层的错误类型，cause记录最内层的根因，如
+// error_chain="save > write > disk gone" error_types="*fmt.wrapError > *fmt.wrapError > *fs.PathError" cause="disk gone"
+// errors.Join等Unwrap() []error的错误逐个展开放在[]中、以|分隔，cause为各个根因以; 连接
+// 在父对象上开启时Named、With创建的子对象也生效
+func (l *Logger) SetErrorChain(enable bool) {
+	var v int32
+	if enable {
+		v = 1
+	}
+	atomic.StoreInt32(&l.errorChain, v)
+}
+
+// 同Logger.SetErrorChain，作用于默认日志对象
+func SetErrorChain(enable bool) {
+	logger.SetErrorChain(enable)
+}
+
+// 取参数中的第一个error生成错误链字段，未开启或没有error时返回空
+func (l *Logger) errorFields(args []interface{}) []field {
+	if atomic.LoadInt32(&l.errorChain) == 0 && atomic.LoadInt32(&l.backend().errorChain) == 0 {
+		return nil
+	}
+	for _, arg := range args {
+		err, ok := arg.(error)
+		if !ok || err == nil {
+			continue
+		}
+		var c errorChain
+		msgs, types := c.walk(err)
+		return []field{
+			{key: "error_chain", value: msgs},
+			{key: "error_types", value: types},
+			{key: "cause", value: strings.Join(c.causes, "; ")},
+		}
+	}
+	return nil
+}
+
+// 展开错误链时收集的根因
+type errorChain struct {
+	causes []string
+}
+
+// 展开err及其内层错误，返回各层消息和类型组成的链
+func (c *errorChain) walk(err error) (msgs, types string) {
+	inner := unwrapAll(err)
+	own := ownMessage(err, inner)
+	types = fmt.Sprintf("%T", err)
+	switch len(inner) {
+	case 0:
+		c.causes = append(c.causes, err.Error())
+		return own, types
+	case 1:
+		innerMsgs, innerTypes := c.walk(inner[0])
+		return joinChain(own, innerMsgs), types + " > " + innerTypes
+	}
+	branchMsgs := make([]string, len(inner))
+	branchTypes := make([]string, len(inner))
+	for i, e := range inner {
+		branchMsgs[i], branchTypes[i] = c.walk(e)
+	}
+	msgs = "[" + strings.Join(branchMsgs, " | ") + "]"
+	types += " > [" + strings.Join(branchTypes, " | ") + "]"
+	return joinChain(own, msgs), types
+}
+
+// 获取直接包装的内层错误，支持Unwrap() error和Unwrap() []error
+func unwrapAll(err error) []error {
+	switch e := err.(type) {
+	case interface{ Unwrap() []error }:
+		var inner []error
+		for _, item := range e.Unwrap() {
+			if item != nil {
+				inner = append(inner, item)
+			}
+		}
+		return inner
+	default:
+		if item := errors.Unwrap(err); item != nil {
+			return []error{item}
+		}
+		return nil
+	}
+}
+
+// 获取该层错误自身的消息：包装单个错误且以其消息结尾时去掉该部分及分隔的": "，
+// 消息只是各内层错误以换行连接时（如errors.Join）为空，其他情况为完整消息
+func ownMessage(err error, inner []error) string {
+	msg := err.Error()
+	switch len(inner) {
+	case 0:
+		return msg
+	case 1:
+		if rest := strings.TrimSuffix(msg, inner[0].Error()); rest != msg {
+			return strings.TrimRight(rest, ": ")
+		}
+		return msg
+	}
+	parts := make([]string, len(inner))
+	for i, e := range inner {
+		parts[i] = e.Error()
+	}
+	if msg == strings.Join(parts, "\n") {
+		return ""
+	}
+	return msg
+}
+
+// 以>连接外层与内层的消息，没有自身消息的层省略
+func joinChain(outer, inner string) string {
+	if outer == "" {
+		return inner
+	}
+	if inner == "" {
+		return outer
+	}
+	return outer + " > " + inner
+}
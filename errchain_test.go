@@ -0,0 +1,98 @@
+package MyLog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// 错误链最内层的错误类型
+type rootErr struct{}
+
+func (*rootErr) Error() string { return "disk gone" }
+
+func TestErrorChainLayers(t *testing.T) {
+	l, buf := newTestLogger(t, WithFlags(FLAG_NONE))
+	l.SetErrorChain(true)
+	err := fmt.Errorf("save: %w", fmt.Errorf("write: %w", &rootErr{}))
+	l.Error(err)
+	l.Flush()
+	// 每一层自身的消息出现在error_chain中，类型出现在error_types中，cause为最内层的错误
+	want := `error_chain="save > write > disk gone" error_types="*fmt.wrapError > *fmt.wrapError > *MyLog.rootErr" cause="disk gone" save: write: disk gone` + "\n"
+	if got := buf.String(); got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+// errors.Join的每个错误分别展开
+func TestErrorChainJoin(t *testing.T) {
+	l, _ := newTestLogger(t)
+	l.SetErrorChain(true)
+	err := fmt.Errorf("sync: %w", errors.Join(fmt.Errorf("open: %w", &rootErr{}), errors.New("timeout")))
+	got := map[string]interface{}{}
+	for _, f := range l.errorFields([]interface{}{"failed", err}) {
+		got[f.key] = f.value
+	}
+	want := map[string]interface{}{
+		"error_chain": "sync > [open > disk gone | timeout]",
+		"error_types": "*fmt.wrapError > *errors.joinError > [*fmt.wrapError > *MyLog.rootErr | *errors.errorString]",
+		"cause":       "disk gone; timeout",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+func TestErrorChainEveryErrorPath(t *testing.T) {
+	code := stubExit(t)
+	l, buf := newTestLogger(t, WithFlags(FLAG_NONE))
+	// 在父对象上开启，子对象同样生效
+	l.SetErrorChain(true)
+	child := l.Named("c")
+	err := fmt.Errorf("wrap: %w", &rootErr{})
+	paths := map[string]func(){
+		"Error":          func() { l.Error(err) },
+		"Errorf":         func() { l.Errorf("x %v", err) },
+		"Fatal":          func() { l.Fatal(err) },
+		"Fatalf":         func() { l.Fatalf("x %v", err) },
+		"Entry.Error":    func() { l.WithFields(Fields{"k": 1}).Error(err) },
+		"Entry.Errorf":   func() { l.WithFields(Fields{"k": 1}).Errorf("x %v", err) },
+		"Entry.Fatal":    func() { l.WithFields(Fields{"k": 1}).Fatal(err) },
+		"ErrorCtx":       func() { l.ErrorCtx(context.Background(), err) },
+		"FatalCtx":       func() { l.FatalCtx(context.Background(), err) },
+		"ErrorWithFlags": func() { l.ErrorWithFlags(FLAG_NONE, err) },
+		"FatalWithFlags": func() { l.FatalWithFlags(FLAG_NONE, err) },
+		"ErrorBatch":     func() { l.ErrorBatch([]interface{}{err}) },
+		"slog":           func() { slog.New(l.NewSlogHandler()).Error("x", "err", err) },
+		"child":          func() { child.Error(err) },
+	}
+	for name, fn := range paths {
+		buf.Reset()
+		fn()
+		l.Flush()
+		if got := buf.String(); !strings.Contains(got, `error_chain="wrap > disk gone" error_types="*fmt.wrapError > *MyLog.rootErr" cause="disk gone"`) {
+			t.Errorf("%s: got %q", name, got)
+		}
+	}
+	if *code != 1 {
+		t.Fatalf("exit code %d", *code)
+	}
+}
+
+func TestErrorChainOnlyWhenEnabled(t *testing.T) {
+	l, buf := newTestLogger(t, WithFlags(FLAG_NONE))
+	err := fmt.Errorf("wrap: %w", &rootErr{})
+	l.Error(err)
+	// 低于ERROR等级不展开
+	l.SetErrorChain(true)
+	l.Warning(err)
+	l.Error("no error value")
+	l.Flush()
+	if got := buf.String(); got != "wrap: disk gone\nwrap: disk gone\nno error value\n" {
+		t.Fatalf("got %q", got)
+	}
+}
@@ -0,0 +1,31 @@
+package MyLog
+
+import (
+	"fmt"
+	"os"
+)
+
+// 设置输出出错时的回调，如文件打开、写入、滚动失败，未设置时错误信息输出到标准错误
+// 回调在输出协程中执行，不应阻塞，也不应通过同一日志对象输出日志
+func (l *Logger) OnError(fn func(err error)) {
+	l.errMu.Lock()
+	defer l.errMu.Unlock()
+	l.onError = fn
+}
+
+// 同Logger.OnError，作用于默认日志对象
+func OnError(fn func(err error)) {
+	logger.OnError(fn)
+}
+
+// 上报输出过程中的错误，只获取errMu，可在不持有mu的后台协程中调用
+func (l *Logger) reportError(err error) {
+	l.errMu.Lock()
+	onError := l.onError
+	l.errMu.Unlock()
+	if onError != nil {
+		onError(err)
+		return
+	}
+	fmt.Fprintln(os.Stderr, "MyLog:", err)
+}
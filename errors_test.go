@@ -0,0 +1,152 @@
+package MyLog
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestOnErrorUnwritableFile(t *testing.T) {
+	// 以普通文件作为目录，无论是否为root均无法创建日志文件
+	blocker := filepath.Join(t.TempDir(), "blocker")
+	if err := os.WriteFile(blocker, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	// 文件无法打开时日志改写到标准错误
+	captureStderr(t)
+	var sink errorSink
+	l, _ := newTestLogger(t, WithOutputType(ONLY_FILE), WithFile(filepath.Join(blocker, "sub", "a.log")))
+	l.OnError(sink.report)
+	l.Info("lost?")
+	l.Flush()
+	errs := sink.Errors()
+	if len(errs) == 0 || !strings.Contains(errs[0].Error(), "open file failed") {
+		t.Fatalf("errors = %v", errs)
+	}
+}
+
+func TestOnErrorWriterFailure(t *testing.T) {
+	var sink errorSink
+	l, _ := newTestLogger(t, WithOutput(failWriter{}))
+	l.OnError(sink.report)
+	l.Info("x")
+	l.Flush()
+	errs := sink.Errors()
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "write terminal failed: device full") {
+		t.Fatalf("errors = %v", errs)
+	}
+}
+
+// 写入时panic的输出目标
+type panicWriter struct{}
+
+func (panicWriter) Write(p []byte) (int, error) {
+	panic("boom")
+}
+
+func TestRecoverFromWriterPanic(t *testing.T) {
+	// 恢复的panic打印到标准错误
+	stderr := captureStderr(t)
+	l, buf := newTestLogger(t)
+	l.AddWriter(panicWriter{})
+	l.Info("first")
+	l.Flush()
+	l.RemoveWriter(panicWriter{})
+	l.Info("second")
+	l.Flush()
+	// 输出协程在panic后继续处理后续日志
+	if !strings.Contains(buf.String(), "[INFO   ] second\n") {
+		t.Fatalf("got %q", buf.String())
+	}
+	if got := stderr(); !strings.Contains(got, "recovered from panic while writing log: boom") {
+		t.Fatalf("stderr got %q", got)
+	}
+}
+
+func TestFileOpenFailureDoesNotCrash(t *testing.T) {
+	blocker := filepath.Join(t.TempDir(), "blocker")
+	if err := os.WriteFile(blocker, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	captureStderr(t)
+	var sink errorSink
+	l, _ := newTestLogger(t, WithOutputType(ONLY_FILE), WithFile(filepath.Join(blocker, "a.log")))
+	l.OnError(sink.report)
+	// 文件未能打开时fileObj为nil，多次写入、刷新和轮转都不应panic
+	for i := 0; i < 3; i++ {
+		l.Info(i)
+	}
+	l.Flush()
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if len(sink.Errors()) == 0 {
+		t.Fatal("open failure not reported")
+	}
+}
+
+func TestSettersReturnErrors(t *testing.T) {
+	blocker := filepath.Join(t.TempDir(), "blocker")
+	if err := os.WriteFile(blocker, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	// 文件写入失败时改写到标准错误
+	captureStderr(t)
+	l, _ := newFileLogger(t)
+	if err := l.SetFilePath(filepath.Join(blocker, "dir")); err == nil {
+		t.Error("SetFilePath under a regular file returned nil")
+	}
+	if err := l.SetFilePath(filepath.Dir(blocker)); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.SetFileName(filepath.Join("blocker", "a.log")); err == nil {
+		t.Error("SetFileName under a regular file returned nil")
+	}
+	if err := l.SetErrorFile(filepath.Join(blocker, "errors.log")); err == nil {
+		t.Error("SetErrorFile under a regular file returned nil")
+	}
+	if err := l.SetRemote("tcp", "127.0.0.1:0"); err == nil {
+		t.Error("SetRemote to port 0 returned nil")
+	}
+	if err := l.SetOutputTypeByName("nowhere"); err == nil {
+		t.Error("SetOutputTypeByName with an unknown name returned nil")
+	}
+	// 恢复可写的文件名后重新生效
+	if err := l.SetFileName("ok.log"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestNilFileFallsBackToStderr(t *testing.T) {
+	blocker := filepath.Join(t.TempDir(), "blocker")
+	if err := os.WriteFile(blocker, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	stderr := captureStderr(t)
+	var sink errorSink
+	var term bytes.Buffer
+	l, _ := newTestLogger(t, WithOutputType(BOTH_TERMINAL_AND_FILE), WithOutput(&term), WithFile(filepath.Join(blocker, "a.log")))
+	l.OnError(sink.report)
+	l.Info("one")
+	l.Info("two")
+	l.Flush()
+	// 终端输出不受影响，文件输出改写到标准错误
+	if got := term.String(); got != "[INFO   ] one\n[INFO   ] two\n" {
+		t.Fatalf("terminal got %q", got)
+	}
+	if got := stderr(); got != "[INFO   ] one\n[INFO   ] two\n" {
+		t.Fatalf("stderr got %q", got)
+	}
+	// 改写到标准错误的提示只出现一次
+	warnings := 0
+	for _, err := range sink.Errors() {
+		if strings.Contains(err.Error(), "writing file output to stderr") {
+			warnings++
+		}
+	}
+	if warnings != 1 {
+		t.Fatalf("fallback warned %d times: %v", warnings, sink.Errors())
+	}
+}
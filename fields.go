@@ -0,0 +1,173 @@
+package MyLog
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// 结构化字段，输出时按key排序渲染为key=value
+type Fields map[string]interface{}
+
+// 附加在日志中的键值对字段
+type field struct {
+	key   string
+	value interface{}
+}
+
+// 带结构化字段的日志条目，由WithFields创建
+type Entry struct {
+	logger *Logger
+	fields []field
+}
+
+// 全局字段，附加在所有日志对象输出的每条日志中，只整体替换不原地修改
+var (
+	globalMu     sync.RWMutex
+	globalFields []field
+)
+
+// 设置全局字段，如服务名、部署ID，所有日志对象的每条日志都会带上，已存在的同名字段被替换
+// 字段按全局字段、With设置的字段、单条日志的字段的顺序合并，同名字段以后者为准
+func SetGlobalField(key string, value interface{}) {
+	globalMu.Lock()
+	defer globalMu.Unlock()
+	fields := make([]field, 0, len(globalFields)+1)
+	for _, f := range globalFields {
+		if f.key != key {
+			fields = append(fields, f)
+		}
+	}
+	globalFields = append(fields, field{key: key, value: value})
+}
+
+// 获取当前的全局字段
+func getGlobalFields() []field {
+	globalMu.RLock()
+	defer globalMu.RUnlock()
+	return globalFields
+}
+
+// 创建带结构化字段的日志条目
+func (l *Logger) WithFields(fields Fields) *Entry {
+	return &Entry{logger: l, fields: mergeFields(nil, fields)}
+}
+
+// 同Logger.WithFields，作用于默认日志对象
+func WithFields(fields Fields) *Entry {
+	return logger.WithFields(fields)
+}
+
+// 在当前条目的基础上追加字段，返回新的条目，同名字段以新值为准
+func (e *Entry) WithFields(fields Fields) *Entry {
+	return &Entry{logger: e.logger, fields: mergeFields(e.fields, fields)}
+}
+
+// 合并字段并按key排序，保证输出顺序稳定
+func mergeFields(base []field, fields Fields) []field {
+	merged := make(Fields, len(base)+len(fields))
+	for _, f := range base {
+		merged[f.key] = f.value
+	}
+	for k, v := range fields {
+		merged[k] = v
+	}
+	result := make([]field, 0, len(merged))
+	for k, v := range merged {
+		result = append(result, field{key: k, value: v})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].key < result[j].key
+	})
+	return result
+}
+
+// 在固定字段之后追加单条日志的字段，同名字段以后者为准
+func joinFields(base, extra []field) []field {
+	if len(base) == 0 {
+		return extra
+	}
+	if len(extra) == 0 {
+		return base
+	}
+	result := make([]field, 0, len(base)+len(extra))
+	for _, f := range base {
+		overridden := false
+		for _, e := range extra {
+			if e.key == f.key {
+				overridden = true
+				break
+			}
+		}
+		if !overridden {
+			result = append(result, f)
+		}
+	}
+	return append(result, extra...)
+}
+
+// 信息输出
+func (e *Entry) Info(args ...interface{}) {
+	e.logger.handleLogMsg(INFO, sprintln(args...), e.fields...)
+}
+
+// 警告信息输出
+func (e *Entry) Warning(args ...interface{}) {
+	e.logger.handleLogMsg(WARNING, sprintln(args...), e.fields...)
+}
+
+// 错误信息输出
+func (e *Entry) Error(args ...interface{}) {
+	e.logger.handleLogMsg(ERROR, argsMsg{text: sprintln(args...), args: args}, e.fields...)
+}
+
+// 严重错误信息输出，输出完毕后退出进程
+func (e *Entry) Fatal(args ...interface{}) {
+	e.logger.handleLogMsg(FATAL, argsMsg{text: sprintln(args...), args: args}, e.fields...)
+	e.logger.flush()
+	exit(1)
+}
+
+// 格式化信息输出
+func (e *Entry) Infof(format string, args ...interface{}) {
+	e.logger.handleLogMsg(INFO, fmt.Sprintf(format, args...), e.fields...)
+}
+
+// 格式化警告信息输出
+func (e *Entry) Warningf(format string, args ...interface{}) {
+	e.logger.handleLogMsg(WARNING, fmt.Sprintf(format, args...), e.fields...)
+}
+
+// 格式化错误信息输出
+func (e *Entry) Errorf(format string, args ...interface{}) {
+	e.logger.handleLogMsg(ERROR, argsMsg{text: fmt.Sprintf(format, args...), args: args}, e.fields...)
+}
+
+// 格式化严重错误信息输出，输出完毕后退出进程
+func (e *Entry) Fatalf(format string, args ...interface{}) {
+	e.logger.handleLogMsg(FATAL, argsMsg{text: fmt.Sprintf(format, args...), args: args}, e.fields...)
+	e.logger.flush()
+	exit(1)
+}
+
+// 将字段格式化为key=value形式，每个字段后跟一个空格
+func formatFields(fields []field) string {
+	var b strings.Builder
+	for _, f := range fields {
+		b.WriteString(f.key)
+		b.WriteByte('=')
+		b.WriteString(quoteValue(fmt.Sprint(f.value)))
+		b.WriteByte(' ')
+	}
+	return b.String()
+}
+
+// 值为空或包含空格、等号、引号时加引号并转义
+func quoteValue(value string) string {
+	if value == "" || strings.ContainsAny(value, " =\"\t\r\n") {
+		return strconv.Quote(value)
+	}
+	return value
+}
@@ -0,0 +1,78 @@
+package MyLog
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWithFieldsOrderingAndEscaping(t *testing.T) {
+	stubExit(t)
+	l, buf := newTestLogger(t)
+	e := l.WithFields(Fields{"user": 7, "action": "login", "note": "has space", "quote": `say "hi"`})
+	e.Info("ok")
+	e.WithFields(Fields{"user": 8, "b": ""}).Errorf("n=%d", 1)
+	l.Flush()
+	want := []string{
+		`[INFO   ] action=login note="has space" quote="say \"hi\"" user=7 ok`,
+		`[ERROR  ] action=login b="" note="has space" quote="say \"hi\"" user=8 n=1`,
+	}
+	if got := lines(buf); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("got %q", got)
+	}
+}
+
+func TestWithCloneIsIndependent(t *testing.T) {
+	l, buf := newTestLogger(t)
+	clone := l.With(Fields{"req": 7})
+	clone.SetFlags(FLAG_NONE)
+	clone.SetLevel(WARNING)
+	clone.Info("filtered")
+	clone.Warning("clone")
+	l.Info("parent")
+	// 在克隆上添加字段不影响原有克隆
+	clone.With(Fields{"user": "bob"}).Warning("nested")
+	clone.Warning("again")
+	l.Flush()
+	// 修改克隆的flags、等级不影响父对象，两者共享同一输出
+	want := []string{
+		"req=7 clone",
+		"[INFO   ] parent",
+		"req=7 user=bob nested",
+		"req=7 again",
+	}
+	if got := lines(buf); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+	if l.Flags != FLAG_LEVEL || l.GetLevel() != DEBUG {
+		t.Fatalf("parent changed: flags %08b level %v", l.Flags, l.GetLevel())
+	}
+}
+
+func TestGlobalFields(t *testing.T) {
+	t.Cleanup(func() {
+		globalMu.Lock()
+		globalFields = nil
+		globalMu.Unlock()
+	})
+	SetGlobalField("svc", "api")
+	SetGlobalField("dc", "east")
+	SetGlobalField("svc", "web")
+	a, bufA := newTestLogger(t)
+	b, bufB := newTestLogger(t, WithFlags(FLAG_NONE))
+	a.Info("plain")
+	// 单条日志、With设置的同名字段覆盖全局字段，其余字段保持顺序
+	a.WithFields(Fields{"dc": "west"}).Info("override")
+	b.With(Fields{"svc": "worker"}).Info("derived")
+	a.Flush()
+	b.Flush()
+	wantA := []string{
+		"[INFO   ] dc=east svc=web plain",
+		"[INFO   ] svc=web dc=west override",
+	}
+	if got := lines(bufA); strings.Join(got, "\n") != strings.Join(wantA, "\n") {
+		t.Fatalf("a got %q, want %q", got, wantA)
+	}
+	if got := bufB.String(); got != "dc=east svc=worker derived\n" {
+		t.Fatalf("b got %q", got)
+	}
+}
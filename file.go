@@ -0,0 +1,500 @@
+package MyLog
+
+import (
+	"bufio"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// 按时间滚动日志文件的周期
+type RotationInterval uint8
+
+const (
+	ROTATE_NONE   RotationInterval = iota // 不按时间滚动
+	ROTATE_DAILY                          // 每天一个文件
+	ROTATE_HOURLY                         // 每小时一个文件
+)
+
+// 获取时间t所在周期的文件名标识
+func (r RotationInterval) stamp(t time.Time) string {
+	switch r {
+	case ROTATE_DAILY:
+		return t.Format("2006-01-02")
+	case ROTATE_HOURLY:
+		return t.Format("2006-01-02-15")
+	}
+	return ""
+}
+
+// 设置单个日志文件的最大字节数，超过后自动滚动，小于等于0表示不限制
+func (l *Logger) SetMaxFileSize(bytes int64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.maxFileSize = bytes
+}
+
+// 同Logger.SetMaxFileSize，作用于默认日志对象
+func SetMaxFileSize(bytes int64) {
+	logger.SetMaxFileSize(bytes)
+}
+
+// 设置滚动后保留的备份文件数量，超出的最旧备份将被删除，0表示全部保留
+func (l *Logger) SetMaxBackups(n int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.maxBackups = n
+}
+
+// 同Logger.SetMaxBackups，作用于默认日志对象
+func SetMaxBackups(n int) {
+	logger.SetMaxBackups(n)
+}
+
+// 设置按时间滚动的周期，文件名中会插入日期，如test-2024-06-01.log
+func (l *Logger) SetRotationInterval(interval RotationInterval) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.rotation = interval
+}
+
+// 同Logger.SetRotationInterval，作用于默认日志对象
+func SetRotationInterval(interval RotationInterval) {
+	logger.SetRotationInterval(interval)
+}
+
+// 获取当前应写入的文件名，按时间滚动时在扩展名前插入周期标识
+func (l *Logger) currentFileName() string {
+	if l.rotation == ROTATE_NONE || l.period == "" {
+		return l.fileName
+	}
+	ext := filepath.Ext(l.fileName)
+	return strings.TrimSuffix(l.fileName, ext) + "-" + l.period + ext
+}
+
+// 日志文件的打开方式，首次打开、滚动及重新打开时均使用追加模式
+// 多个进程以O_APPEND写入同一文件时，每次write的内容会整体追加到文件末尾，不会相互覆盖；
+// 每行日志（使用缓冲时为若干完整的行）只通过一次write写入，本地文件系统上行不会被拆散。
+// 注意按大小滚动由各进程独立判断，多进程共享同一文件时应关闭按大小滚动，交由外部工具处理
+const fileOpenFlag = os.O_CREATE | os.O_WRONLY | os.O_APPEND
+
+// 未调用SetFileMode时新建日志文件的权限，Windows下只区分是否只读，0644创建的是普通可写文件
+// 使用默认权限时创建的文件受umask影响，已有文件的权限不做修改
+const defaultFileMode os.FileMode = 0644
+
+// 日志文件的权限，未设置时为defaultFileMode
+func (l *Logger) openMode() os.FileMode {
+	if l.fileMode == 0 {
+		return defaultFileMode
+	}
+	return l.fileMode
+}
+
+// 由文件权限得到目录权限：可读的用户同时可进入目录，所有者始终拥有全部权限
+// 如0644 -> 0755，0640 -> 0750，0600 -> 0700
+func dirMode(mode os.FileMode) os.FileMode {
+	return mode&os.ModePerm | (mode&0444)>>2 | 0700
+}
+
+// 创建日志目录，设置了权限时新建的目录同样不受umask影响，已有目录的权限不做修改
+func (l *Logger) makeDir(mode os.FileMode) error {
+	_, err := os.Stat(l.filePath)
+	if !os.IsNotExist(err) {
+		return nil
+	}
+	if err := os.MkdirAll(l.filePath, dirMode(mode)); err != nil {
+		return err
+	}
+	if l.fileMode != 0 {
+		return os.Chmod(l.filePath, dirMode(mode))
+	}
+	return nil
+}
+
+// 打开日志文件，并记录当前文件大小，目录不存在时先创建
+func (l *Logger) openFile() error {
+	if l.rotation != ROTATE_NONE && l.period == "" {
+		l.period = l.rotation.stamp(l.now())
+	}
+	mode := l.openMode()
+	if err := l.makeDir(mode); err != nil {
+		return err
+	}
+	fileObj, err := os.OpenFile(filepath.Join(l.filePath, l.currentFileName()), fileOpenFlag, mode)
+	if err != nil {
+		return err
+	}
+	// 创建时的权限会被umask屏蔽，且已有文件的权限不会改变，设置了权限时显式修改
+	if l.fileMode != 0 {
+		if err := fileObj.Chmod(l.fileMode); err != nil {
+			fileObj.Close()
+			return err
+		}
+	}
+	l.fileObj = fileObj
+	l.fileFallback = false
+	if l.flushInterval > 0 {
+		l.fileBuf = bufio.NewWriter(fileObj)
+	}
+	l.fileSize = 0
+	if info, err := fileObj.Stat(); err == nil {
+		l.fileSize = info.Size()
+	}
+	return nil
+}
+
+// 关闭当前文件，下次写入时按新的文件名和路径重新打开
+func (l *Logger) resetFile() {
+	if err := l.closeFile(); err != nil {
+		l.reportError(fmt.Errorf("close file failed: %w", err))
+	}
+	l.fileOpened = false
+	l.period = ""
+}
+
+// 输出类型包含文件时立即打开文件，用于在设置时返回配置错误，调用方需持有锁
+func (l *Logger) openNow() error {
+	if l.OutputType&ONLY_FILE != ONLY_FILE {
+		return nil
+	}
+	l.fileOpened = true
+	if err := l.openFile(); err != nil {
+		return fmt.Errorf("open file failed: %w", err)
+	}
+	return nil
+}
+
+// 关闭并按原路径重新打开日志文件（及错误文件），用于logrotate等外部工具重命名文件之后
+// 重新打开后的日志写入原路径下新建的文件
+func (l *Logger) ReopenFile() error {
+	l.flush()
+	l = l.backend()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := l.reopen(); err != nil {
+		return err
+	}
+	if l.errFile != nil {
+		return l.errFile.reopen()
+	}
+	return nil
+}
+
+// 同Logger.ReopenFile，作用于默认日志对象
+func ReopenFile() error {
+	return logger.ReopenFile()
+}
+
+// 关闭当前文件后重新打开，尚未写入过的文件仍在第一次写入时打开
+func (l *Logger) reopen() error {
+	if err := l.closeFile(); err != nil {
+		return fmt.Errorf("close file failed: %w", err)
+	}
+	if !l.fileOpened {
+		return nil
+	}
+	if err := l.openFile(); err != nil {
+		return fmt.Errorf("open file failed: %w", err)
+	}
+	return nil
+}
+
+// 设置文件写入缓冲的刷新间隔，默认200ms，小于等于0时不使用缓冲、每行直接写入文件
+// 使用缓冲时进程崩溃可能丢失最近一个间隔内的日志，ERROR及以上等级的日志会立即刷新
+func (l *Logger) SetFlushInterval(d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.flushInterval = d
+	if l.fileObj == nil {
+		return
+	}
+	l.flushFileBuf()
+	if d > 0 {
+		l.fileBuf = bufio.NewWriter(l.fileObj)
+	} else {
+		l.fileBuf = nil
+	}
+}
+
+// 同Logger.SetFlushInterval，作用于默认日志对象
+func SetFlushInterval(d time.Duration) {
+	logger.SetFlushInterval(d)
+}
+
+// 将缓冲中的内容写入文件
+func (l *Logger) flushFileBuf() {
+	if l.fileBuf == nil || l.fileBuf.Buffered() == 0 {
+		return
+	}
+	if err := l.fileBuf.Flush(); err != nil {
+		l.reportError(fmt.Errorf("write file failed: %w", err))
+	}
+}
+
+// 刷新缓冲并落盘
+func (l *Logger) syncFile() {
+	if l.fileObj == nil {
+		return
+	}
+	l.flushFileBuf()
+	l.fileObj.Sync()
+}
+
+// 落盘并关闭日志文件
+func (l *Logger) closeFile() error {
+	if l.fileObj == nil {
+		return nil
+	}
+	l.syncFile()
+	err := l.fileObj.Close()
+	l.fileObj = nil
+	l.fileBuf = nil
+	return err
+}
+
+// 写入一行日志（含换行）到文件，跨越时间周期或写入后超过大小上限的先滚动文件
+func (l *Logger) writeFile(line []byte, log *logMsg) {
+	// 第一次写入时打开文件
+	if !l.fileOpened {
+		l.fileOpened = true
+		if err := l.openFile(); err != nil {
+			l.reportError(fmt.Errorf("open file failed: %w", err))
+		}
+	}
+	if l.rotation != ROTATE_NONE {
+		if stamp := l.rotation.stamp(log.at); stamp != l.period {
+			l.period = stamp
+			if err := l.closeFile(); err != nil {
+				l.reportError(fmt.Errorf("close file failed: %w", err))
+			}
+			if err := l.openFile(); err != nil {
+				l.reportError(fmt.Errorf("open file failed: %w", err))
+			}
+		}
+	}
+	size := int64(len(line))
+	if l.maxFileSize > 0 && l.fileSize > 0 && l.fileSize+size > l.maxFileSize {
+		if err := l.rotate(); err != nil {
+			l.reportError(fmt.Errorf("rotate file failed: %w", err))
+		}
+	}
+	// 文件打开失败时改为写入标准错误，避免日志丢失，只提示一次
+	if l.fileObj == nil {
+		if !l.fileFallback {
+			l.fileFallback = true
+			l.reportError(errors.New("log file unavailable, writing file output to stderr"))
+		}
+		if _, err := os.Stderr.Write(line); err != nil {
+			l.reportError(fmt.Errorf("write stderr failed: %w", err))
+		}
+		return
+	}
+	n, err := l.writeFileData(line)
+	l.fileSize += int64(n)
+	if err != nil {
+		l.reportError(fmt.Errorf("write file failed: %w", err))
+	}
+	// 高等级日志及同步模式立即刷新，其余等待定时刷新
+	if l.fileBuf != nil && l.fileBuf.Buffered() > 0 {
+		if log.level >= ERROR || atomic.LoadInt32(&l.syncMode) != 0 {
+			l.flushFileBuf()
+		} else if l.flushC == nil {
+			l.flushC = time.After(l.flushInterval)
+		}
+	}
+}
+
+// 写入文件或缓冲，保证同一行不会被拆分到两次系统调用中
+func (l *Logger) writeFileData(line []byte) (int, error) {
+	if l.fileBuf == nil {
+		return l.fileObj.Write(line)
+	}
+	if len(line) > l.fileBuf.Available() {
+		l.flushFileBuf()
+		// 超过缓冲大小的行直接写入文件
+		if len(line) > l.fileBuf.Available() {
+			return l.fileObj.Write(line)
+		}
+	}
+	return l.fileBuf.Write(line)
+}
+
+// 设置单独的错误日志文件，WARNING及以上等级的日志会额外写入该文件，传空字符串取消
+// 错误文件按设置时的大小、时间滚动及备份规则独立滚动，不使用写入缓冲，文件打开失败时返回错误
+func (l *Logger) SetErrorFile(file string) error {
+	l.flush()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.errFile != nil {
+		if err := l.errFile.closeFile(); err != nil {
+			l.reportError(fmt.Errorf("close error file failed: %w", err))
+		}
+		l.errFile.compressWG.Wait()
+		l.errFile = nil
+	}
+	if file == "" {
+		return nil
+	}
+	dir, name := filepath.Split(file)
+	if dir == "" {
+		dir = l.filePath
+	}
+	l.errFile = &Logger{
+		OutputType:  ONLY_FILE,
+		fileName:    name,
+		filePath:    dir,
+		maxFileSize: l.maxFileSize,
+		maxBackups:  l.maxBackups,
+		compress:    l.compress,
+		rotation:    l.rotation,
+		fileMode:    l.fileMode,
+		onError:     l.reportError,
+	}
+	return l.errFile.openNow()
+}
+
+// 同Logger.SetErrorFile，作用于默认日志对象
+func SetErrorFile(file string) error {
+	return logger.SetErrorFile(file)
+}
+
+// 设置日志文件的权限，如含敏感信息时使用0600、同组共享时使用0640
+// 在打开、滚动及重新打开文件时生效，不受umask影响，目录权限按文件权限推算（0600对应0700）
+// 已打开的日志文件及错误日志文件立即修改为该权限
+func (l *Logger) SetFileMode(mode os.FileMode) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.fileMode = mode & os.ModePerm
+	files := []*Logger{l}
+	if l.errFile != nil {
+		l.errFile.fileMode = l.fileMode
+		files = append(files, l.errFile)
+	}
+	for _, f := range files {
+		if f.fileObj == nil {
+			continue
+		}
+		if err := f.fileObj.Chmod(f.openMode()); err != nil {
+			return fmt.Errorf("chmod file failed: %w", err)
+		}
+	}
+	return nil
+}
+
+// 同Logger.SetFileMode，作用于默认日志对象
+func SetFileMode(mode os.FileMode) error {
+	return logger.SetFileMode(mode)
+}
+
+// 设置滚动后是否在后台将备份文件压缩为gzip，如test.log.1.gz
+func (l *Logger) SetCompressBackups(enable bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.compress = enable
+}
+
+// 同Logger.SetCompressBackups，作用于默认日志对象
+func SetCompressBackups(enable bool) {
+	logger.SetCompressBackups(enable)
+}
+
+// 滚动日志文件：xxx.log -> xxx.log.1，已有备份序号依次加一
+func (l *Logger) rotate() error {
+	name := filepath.Join(l.filePath, l.currentFileName())
+	if err := l.closeFile(); err != nil {
+		return err
+	}
+	// 等待上一次的压缩完成，避免备份序号变化后压缩错文件
+	l.compressWG.Wait()
+
+	// 统计已有的备份数量
+	count := 0
+	for backupExists(name, count+1) {
+		count++
+	}
+	// 删除超出保留数量的备份
+	if l.maxBackups > 0 {
+		for ; count >= l.maxBackups; count-- {
+			os.Remove(backupName(name, count))
+			os.Remove(backupName(name, count) + ".gz")
+		}
+	}
+	for i := count; i >= 1; i-- {
+		for _, ext := range []string{"", ".gz"} {
+			if _, err := os.Stat(backupName(name, i) + ext); err != nil {
+				continue
+			}
+			if err := os.Rename(backupName(name, i)+ext, backupName(name, i+1)+ext); err != nil {
+				return err
+			}
+		}
+	}
+	if err := os.Rename(name, backupName(name, 1)); err != nil {
+		return err
+	}
+	if l.compress {
+		l.compressWG.Add(1)
+		go l.compressBackup(backupName(name, 1), l.openMode())
+	}
+	return l.openFile()
+}
+
+// 获取第n个备份文件名
+func backupName(name string, n int) string {
+	return fmt.Sprintf("%s.%d", name, n)
+}
+
+// 判断第n个备份（压缩或未压缩）是否存在
+func backupExists(name string, n int) bool {
+	for _, ext := range []string{"", ".gz"} {
+		if _, err := os.Stat(backupName(name, n) + ext); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// 将备份文件压缩为.gz后删除原文件，在后台协程中执行
+// 滚动时输出协程持有读锁等待压缩完成，此处不能获取mu，否则有设置函数等待写锁时会死锁
+func (l *Logger) compressBackup(src string, mode os.FileMode) {
+	defer l.compressWG.Done()
+	if err := gzipFile(src, src+".gz", mode); err != nil {
+		l.reportError(fmt.Errorf("compress backup failed: %w", err))
+		return
+	}
+	os.Remove(src)
+}
+
+// 将src以mode权限压缩写入dst，先写临时文件，完成后再重命名，避免留下不完整的压缩文件
+func gzipFile(src, dst string, mode os.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	tmp := dst + ".tmp"
+	out, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+	zw := gzip.NewWriter(out)
+	_, err = io.Copy(zw, in)
+	if err == nil {
+		err = zw.Close()
+	}
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, dst)
+}
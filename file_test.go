@@ -0,0 +1,488 @@
+package MyLog
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// 创建只输出到临时目录中test.log的日志对象，返回日志文件路径
+func newFileLogger(t *testing.T, opts ...Option) (*Logger, string) {
+	t.Helper()
+	dir := t.TempDir()
+	base := []Option{WithOutputType(ONLY_FILE), WithFile(filepath.Join(dir, "test.log")), WithFlags(FLAG_LEVEL)}
+	l := New(append(base, opts...)...)
+	t.Cleanup(func() { l.Close() })
+	return l, filepath.Join(dir, "test.log")
+}
+
+// 读取文件内容，文件不存在时返回空
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestFlushWritesAllToFile(t *testing.T) {
+	l, path := newFileLogger(t)
+	const n = 500
+	for i := 0; i < n; i++ {
+		l.Info("line", i)
+	}
+	l.Flush()
+	got := strings.Count(readFile(t, path), "\n")
+	if got != n {
+		t.Fatalf("file has %d lines after Flush, want %d", got, n)
+	}
+}
+
+func TestSizeRotation(t *testing.T) {
+	l, path := newFileLogger(t, WithFlags(FLAG_NONE))
+	l.SetMaxFileSize(100)
+	l.SetMaxBackups(2)
+	line := strings.Repeat("x", 39) // 加换行共40字节，每个文件容纳2行
+	for i := 0; i < 10; i++ {
+		l.Info(line)
+	}
+	l.Flush()
+	for _, name := range []string{path, path + ".1", path + ".2"} {
+		if got := readFile(t, name); got != strings.Repeat(line+"\n", 2) {
+			t.Fatalf("%s = %q", filepath.Base(name), got)
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Fatalf("backup beyond MaxBackups kept: %v", err)
+	}
+}
+
+func TestSizeRotationCountsExistingFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "test.log")
+	if err := os.WriteFile(path, []byte(strings.Repeat("o", 98)+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	l := New(WithOutputType(ONLY_FILE), WithFile(path), WithFlags(FLAG_NONE))
+	defer l.Close()
+	l.SetMaxFileSize(100)
+	l.Info("new")
+	l.Flush()
+	if readFile(t, path) != "new\n" || !strings.HasPrefix(readFile(t, path+".1"), "ooo") {
+		t.Fatalf("existing size not taken into account")
+	}
+}
+
+func TestDailyRotationWithFakeClock(t *testing.T) {
+	clock := newFakeClock(time.Date(2024, 6, 1, 23, 59, 0, 0, time.Local))
+	l, path := newFileLogger(t, WithFlags(FLAG_NONE))
+	l.SetClock(clock.Now)
+	l.SetRotationInterval(ROTATE_DAILY)
+	l.Info("day one")
+	l.Flush()
+	clock.Add(2 * time.Minute)
+	l.Info("day two")
+	l.Flush()
+	dir := filepath.Dir(path)
+	if got := readFile(t, filepath.Join(dir, "test-2024-06-01.log")); got != "day one\n" {
+		t.Fatalf("first day file = %q", got)
+	}
+	if got := readFile(t, filepath.Join(dir, "test-2024-06-02.log")); got != "day two\n" {
+		t.Fatalf("second day file = %q", got)
+	}
+}
+
+func TestHourlyRotationName(t *testing.T) {
+	clock := newFakeClock(time.Date(2024, 6, 1, 9, 30, 0, 0, time.Local))
+	l, path := newFileLogger(t, WithFlags(FLAG_NONE))
+	l.SetClock(clock.Now)
+	l.SetRotationInterval(ROTATE_HOURLY)
+	l.Info("nine")
+	clock.Add(time.Hour)
+	l.Info("ten")
+	l.Flush()
+	dir := filepath.Dir(path)
+	if readFile(t, filepath.Join(dir, "test-2024-06-01-09.log")) != "nine\n" ||
+		readFile(t, filepath.Join(dir, "test-2024-06-01-10.log")) != "ten\n" {
+		t.Fatal("hourly files not created")
+	}
+}
+
+func TestSetFileNameAfterLogging(t *testing.T) {
+	l, path := newFileLogger(t, WithFlags(FLAG_NONE))
+	l.Info("first")
+	if err := l.SetFileName("second.log"); err != nil {
+		t.Fatal(err)
+	}
+	l.Info("second")
+	l.Flush()
+	if got := readFile(t, path); got != "first\n" {
+		t.Fatalf("old file = %q", got)
+	}
+	if got := readFile(t, filepath.Join(filepath.Dir(path), "second.log")); got != "second\n" {
+		t.Fatalf("new file = %q", got)
+	}
+}
+
+func TestSetFilePath(t *testing.T) {
+	base := t.TempDir()
+	l, _ := newFileLogger(t, WithFlags(FLAG_NONE))
+	// 首条日志之前设置，目录不存在时自动创建
+	before := filepath.Join(base, "a", "b")
+	if err := l.SetFilePath(before); err != nil {
+		t.Fatal(err)
+	}
+	l.Info("before")
+	l.Flush()
+	// 开始输出之后再次设置
+	after := filepath.Join(base, "c")
+	if err := l.SetFilePath(after); err != nil {
+		t.Fatal(err)
+	}
+	l.Info("after")
+	l.Flush()
+	if got := readFile(t, filepath.Join(before, "test.log")); got != "before\n" {
+		t.Fatalf("first dir = %q", got)
+	}
+	if got := readFile(t, filepath.Join(after, "test.log")); got != "after\n" {
+		t.Fatalf("second dir = %q", got)
+	}
+}
+
+func TestOutputTypeDestinations(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		typ      OutputType
+		terminal bool
+		file     bool
+	}{
+		{"terminal", ONLY_TERMINAL, true, false},
+		{"file", ONLY_FILE, false, true},
+		{"both", BOTH_TERMINAL_AND_FILE, true, true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var term bytes.Buffer
+			l, path := newFileLogger(t, WithOutputType(tt.typ), WithOutput(&term), WithErrorOutput(&term), WithFlags(FLAG_NONE))
+			l.Info("info")
+			l.Error("error")
+			l.Flush()
+			want := map[bool]string{true: "info\nerror\n", false: ""}
+			if got := term.String(); got != want[tt.terminal] {
+				t.Errorf("terminal = %q", got)
+			}
+			if got := readFile(t, path); got != want[tt.file] {
+				t.Errorf("file = %q", got)
+			}
+		})
+	}
+	if ONLY_TERMINAL&ONLY_FILE != 0 || BOTH_TERMINAL_AND_FILE != ONLY_TERMINAL|ONLY_FILE {
+		t.Fatal("output types are not distinct bit flags")
+	}
+}
+
+func TestBufferedFileFlushBoundary(t *testing.T) {
+	l, path := newFileLogger(t, WithFlags(FLAG_NONE))
+	l.SetFlushInterval(20 * time.Millisecond)
+	for i := 0; i < 3; i++ {
+		l.Info("before", i)
+	}
+	// 等待定时刷新，之后的日志写入新的缓冲
+	deadline := time.Now().Add(2 * time.Second)
+	for readFile(t, path) != "before 0\nbefore 1\nbefore 2\n" {
+		if time.Now().After(deadline) {
+			t.Fatalf("timer flush missing, file = %q", readFile(t, path))
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	for i := 0; i < 3; i++ {
+		l.Info("after", i)
+	}
+	l.Close()
+	want := "before 0\nbefore 1\nbefore 2\nafter 0\nafter 1\nafter 2\n"
+	if got := readFile(t, path); got != want {
+		t.Fatalf("file = %q", got)
+	}
+}
+
+func TestBufferedFileErrorFlushesImmediately(t *testing.T) {
+	l, path := newFileLogger(t, WithFlags(FLAG_NONE))
+	l.SetFlushInterval(time.Hour)
+	// 等待输出协程处理完已提交的消息，但不刷新文件缓冲
+	processed := func() { l.inWriter(func() {}) }
+	l.Info("buffered")
+	processed()
+	if got := readFile(t, path); got != "" {
+		t.Fatalf("INFO written before flush: %q", got)
+	}
+	l.Error("urgent")
+	processed()
+	if got := readFile(t, path); got != "buffered\nurgent\n" {
+		t.Fatalf("ERROR not flushed, file = %q", got)
+	}
+}
+
+func benchmarkFile(b *testing.B, interval time.Duration) {
+	dir := b.TempDir()
+	l := New(WithOutputType(ONLY_FILE), WithFile(filepath.Join(dir, "bench.log")), WithFlags(FLAG_TIME|FLAG_LEVEL))
+	l.SetFlushInterval(interval)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		l.Info("benchmark message", i)
+	}
+	l.Flush()
+	b.StopTimer()
+	l.Close()
+}
+
+func BenchmarkFileBuffered(b *testing.B) {
+	benchmarkFile(b, 200*time.Millisecond)
+}
+
+func BenchmarkFileUnbuffered(b *testing.B) {
+	benchmarkFile(b, 0)
+}
+
+func TestCompressBackups(t *testing.T) {
+	l, path := newFileLogger(t, WithFlags(FLAG_NONE))
+	l.SetMaxFileSize(64)
+	l.SetCompressBackups(true)
+	first := strings.Repeat("a", 39) + "\n" + strings.Repeat("b", 19) + "\n"
+	l.Info(strings.Repeat("a", 39))
+	l.Info(strings.Repeat("b", 19))
+	l.Info("next file")
+	l.Close()
+	f, err := os.Open(path + ".1.gz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != first {
+		t.Fatalf("decompressed = %q", data)
+	}
+	if _, err := os.Stat(path + ".1"); !os.IsNotExist(err) {
+		t.Fatal("uncompressed backup left behind")
+	}
+	if got := readFile(t, path); got != "next file\n" {
+		t.Fatalf("current file = %q", got)
+	}
+}
+
+// 压缩失败时上报错误不获取日志对象的锁，滚动等待压缩期间有设置函数等待写锁也不会死锁
+func TestCompressFailureWithConcurrentSetter(t *testing.T) {
+	var sink errorSink
+	l, path := newFileLogger(t, WithFlags(FLAG_NONE))
+	l.OnError(sink.report)
+	l.SetMaxFileSize(10)
+	l.SetCompressBackups(true)
+	// 临时文件位置是目录，压缩必定失败
+	for i := 1; i <= 3; i++ {
+		if err := os.MkdirAll(fmt.Sprintf("%s.%d.gz.tmp", path, i), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	l.SetMaxBackups(3)
+	stop := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				l.SetLevel(DEBUG)
+			}
+		}
+	}()
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < 50; i++ {
+			l.Info("rotate me")
+		}
+		l.Flush()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("writer deadlocked while compression failed")
+	}
+	close(stop)
+	if len(sink.Errors()) == 0 {
+		t.Fatal("compression failure not reported")
+	}
+}
+
+func TestErrorFileGetsHighSeverityOnly(t *testing.T) {
+	skipIfDebugStripped(t)
+	l, path := newFileLogger(t)
+	errPath := filepath.Join(filepath.Dir(path), "errors.log")
+	if err := l.SetErrorFile(errPath); err != nil {
+		t.Fatal(err)
+	}
+	l.Debug("d")
+	l.Info("i")
+	l.Warning("w")
+	l.Error("e")
+	l.Flush()
+	// 主文件包含所有日志，错误文件只包含WARNING及以上等级
+	if got := readFile(t, path); got != "[DEBUG  ] d\n[INFO   ] i\n[WARNING] w\n[ERROR  ] e\n" {
+		t.Fatalf("main file got %q", got)
+	}
+	if got := readFile(t, errPath); got != "[WARNING] w\n[ERROR  ] e\n" {
+		t.Fatalf("error file got %q", got)
+	}
+
+	// 取消后不再写入错误文件
+	if err := l.SetErrorFile(""); err != nil {
+		t.Fatal(err)
+	}
+	l.Error("after")
+	l.Flush()
+	if got := readFile(t, errPath); got != "[WARNING] w\n[ERROR  ] e\n" {
+		t.Fatalf("error file got %q after removal", got)
+	}
+}
+
+func TestMultiProcessAppend(t *testing.T) {
+	if path := os.Getenv("MYLOG_APPEND_CHILD"); path != "" {
+		// 子进程：与其他进程同时向同一文件追加
+		l := New(WithOutputType(ONLY_FILE), WithFile(path), WithFlags(FLAG_NONE))
+		for i := 0; i < 300; i++ {
+			l.Info(os.Getpid(), i, strings.Repeat("x", 300))
+		}
+		l.Close()
+		return
+	}
+	path := filepath.Join(t.TempDir(), "shared.log")
+	var cmds []*exec.Cmd
+	for i := 0; i < 2; i++ {
+		cmd := exec.Command(os.Args[0], "-test.run=^TestMultiProcessAppend$")
+		cmd.Env = append(os.Environ(), "MYLOG_APPEND_CHILD="+path)
+		if err := cmd.Start(); err != nil {
+			t.Fatal(err)
+		}
+		cmds = append(cmds, cmd)
+	}
+	for _, cmd := range cmds {
+		if err := cmd.Wait(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// 每行为一次完整的write，多个进程的行不会交错或互相覆盖
+	line := regexp.MustCompile(`^\d+ \d+ x{300}$`)
+	got := strings.Split(strings.TrimSuffix(readFile(t, path), "\n"), "\n")
+	if len(got) != 600 {
+		t.Fatalf("got %d lines, want 600", len(got))
+	}
+	for _, s := range got {
+		if !line.MatchString(s) {
+			t.Fatalf("split line %q", s)
+		}
+	}
+}
+
+func TestRotationReopensForAppend(t *testing.T) {
+	l, path := newFileLogger(t, WithFlags(FLAG_NONE))
+	l.SetMaxFileSize(45)
+	for _, c := range "abc" {
+		l.Info(strings.Repeat(string(c), 19)) // 加换行共20字节，第3行滚动到新文件
+	}
+	l.Flush()
+	// 其他进程在滚动后向新文件追加，之后的日志接在其后而不是覆盖
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString("other\n")
+	f.Close()
+	l.Info("x")
+	l.Flush()
+	if got := readFile(t, path); got != strings.Repeat("c", 19)+"\nother\nx\n" {
+		t.Fatalf("got %q", got)
+	}
+}
+
+func TestCreatesNestedLogDir(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "x", "y", "z")
+	path := filepath.Join(dir, "app.log")
+	var sink errorSink
+	l := New(WithOutputType(ONLY_FILE), WithFile(path), WithFlags(FLAG_NONE))
+	t.Cleanup(func() { l.Close() })
+	l.OnError(sink.report)
+	l.Info("created")
+	l.Flush()
+	if got := readFile(t, path); got != "created\n" {
+		t.Fatalf("got %q", got)
+	}
+	if errs := sink.Errors(); len(errs) != 0 {
+		t.Fatalf("errors = %v", errs)
+	}
+	// 目录权限按默认的文件权限0644推算为0755
+	if runtime.GOOS != "windows" {
+		fi, err := os.Stat(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if perm := fi.Mode().Perm(); perm != 0755 {
+			t.Fatalf("dir mode %o, want 755", perm)
+		}
+	}
+}
+
+func TestReopenAfterRename(t *testing.T) {
+	l, path := newFileLogger(t, WithFlags(FLAG_NONE))
+	l.Info("old")
+	l.Flush()
+	// 模拟logrotate：重命名后通知重新打开
+	if err := os.Rename(path, path+".rotated"); err != nil {
+		t.Fatal(err)
+	}
+	l.Info("still old")
+	if err := l.ReopenFile(); err != nil {
+		t.Fatal(err)
+	}
+	l.Info("new")
+	l.Flush()
+	if got := readFile(t, path+".rotated"); got != "old\nstill old\n" {
+		t.Fatalf("rotated file got %q", got)
+	}
+	if got := readFile(t, path); got != "new\n" {
+		t.Fatalf("fresh file got %q", got)
+	}
+}
+
+func TestPerDestinationLevels(t *testing.T) {
+	skipIfDebugStripped(t)
+	var term bytes.Buffer
+	l, path := newFileLogger(t, WithOutputType(BOTH_TERMINAL_AND_FILE), WithOutput(&term), WithErrorToStderr(false))
+	l.SetTerminalLevel(DEBUG)
+	l.SetFileLevel(INFO)
+	l.Trace("t")
+	l.Debug("d")
+	l.Info("i")
+	l.Flush()
+	// 全局等级为下限，TRACE在两个目标中都不输出
+	if got := term.String(); got != "[DEBUG  ] d\n[INFO   ] i\n" {
+		t.Fatalf("terminal got %q", got)
+	}
+	if got := readFile(t, path); got != "[INFO   ] i\n" {
+		t.Fatalf("file got %q", got)
+	}
+}
@@ -0,0 +1,79 @@
+//go:build unix
+
+package MyLog
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+)
+
+// 文件或目录的权限位
+func permOf(t *testing.T, path string) os.FileMode {
+	t.Helper()
+	fi, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fi.Mode().Perm()
+}
+
+func TestFileModeOnCreate(t *testing.T) {
+	// 设置的权限不受umask影响
+	old := syscall.Umask(0077)
+	defer syscall.Umask(old)
+	for _, mode := range []os.FileMode{0600, 0640, 0664} {
+		dir := filepath.Join(t.TempDir(), "logs")
+		path := filepath.Join(dir, "test.log")
+		l := New(WithOutputType(ONLY_FILE), WithFile(path), WithFlags(FLAG_NONE))
+		if err := l.SetFileMode(mode); err != nil {
+			t.Fatal(err)
+		}
+		l.Info("x")
+		l.Close()
+		if got := permOf(t, path); got != mode {
+			t.Errorf("file mode %o, want %o", got, mode)
+		}
+		if got, want := permOf(t, dir), dirMode(mode); got != want {
+			t.Errorf("dir mode %o, want %o", got, want)
+		}
+	}
+}
+
+func TestFileModeOnOpenAndReopen(t *testing.T) {
+	l, path := newFileLogger(t, WithFlags(FLAG_NONE))
+	l.Info("x")
+	l.Flush()
+	if got := permOf(t, path); got&^0644 != 0 {
+		t.Fatalf("default file mode %o", got)
+	}
+	// 已打开的文件立即修改权限
+	if err := l.SetFileMode(0600); err != nil {
+		t.Fatal(err)
+	}
+	if got := permOf(t, path); got != 0600 {
+		t.Fatalf("open file mode %o, want 600", got)
+	}
+	// 重新打开时新建的文件使用设置的权限
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.ReopenFile(); err != nil {
+		t.Fatal(err)
+	}
+	l.Info("y")
+	l.Flush()
+	if got := permOf(t, path); got != 0600 {
+		t.Fatalf("reopened file mode %o, want 600", got)
+	}
+}
+
+func TestDirMode(t *testing.T) {
+	cases := map[os.FileMode]os.FileMode{0644: 0755, 0640: 0750, 0600: 0700, 0604: 0705, 0400: 0700}
+	for mode, want := range cases {
+		if got := dirMode(mode); got != want {
+			t.Errorf("dirMode(%o) = %o, want %o", mode, got, want)
+		}
+	}
+}
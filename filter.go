@@ -0,0 +1,44 @@
+package MyLog
+
+import (
+	"unicode/utf8"
+)
+
+// 设置消息过滤函数，在输出协程中格式化之前调用，可修改消息内容（如脱敏），返回空字符串时丢弃该条日志，传nil取消
+// 过滤函数在持有日志对象的锁时执行，不能在其中调用同一日志对象的设置或输出函数；钩子收到的是过滤前的消息
+func (l *Logger) SetMessageFilter(fn func(level LevelLog, msg string) string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.msgFilter = fn
+}
+
+// 同Logger.SetMessageFilter，作用于默认日志对象
+func SetMessageFilter(fn func(level LevelLog, msg string) string) {
+	logger.SetMessageFilter(fn)
+}
+
+// 截断消息时追加的标记
+const truncatedMark = "…[truncated]"
+
+// 设置消息的最大字节数，超出的部分被截断并追加…[truncated]，前缀和字段不计入，不会截断在多字节字符中间，小于等于0表示不限制
+func (l *Logger) SetMaxLineLength(n int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.maxLineLength = n
+}
+
+// 同Logger.SetMaxLineLength，作用于默认日志对象
+func SetMaxLineLength(n int) {
+	logger.SetMaxLineLength(n)
+}
+
+// 将消息截断到n字节以内，截断位置落在多字节字符中间时向前退到字符边界
+func truncateMsg(msg string, n int) string {
+	if len(msg) <= n {
+		return msg
+	}
+	for n > 0 && !utf8.RuneStart(msg[n]) {
+		n--
+	}
+	return msg[:n] + truncatedMark
+}
@@ -0,0 +1,71 @@
+package MyLog
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestMessageFilterRedacts(t *testing.T) {
+	var term bytes.Buffer
+	l, path := newFileLogger(t, WithOutputType(BOTH_TERMINAL_AND_FILE), WithOutput(&term), WithErrorToStderr(false))
+	email := regexp.MustCompile(`[\w.]+@[\w.]+`)
+	l.SetMessageFilter(func(level LevelLog, msg string) string {
+		if strings.Contains(msg, "token") {
+			return ""
+		}
+		return email.ReplaceAllString(msg, "***")
+	})
+	l.Info("login bob@example.com ok")
+	l.Info("token=abc")
+	l.Warning("no pii")
+	l.Flush()
+	// 终端和文件中都已脱敏，返回空字符串的日志被丢弃
+	want := "[INFO   ] login *** ok\n[WARNING] no pii\n"
+	if got := term.String(); got != want {
+		t.Fatalf("terminal got %q", got)
+	}
+	if got := readFile(t, path); got != want {
+		t.Fatalf("file got %q", got)
+	}
+}
+
+func TestTruncateMsgUTF8(t *testing.T) {
+	tests := []struct {
+		msg  string
+		n    int
+		want string
+	}{
+		{"short", 10, "short"},
+		{"exactly10!", 10, "exactly10!"},
+		{"abcdefghijk", 10, "abcdefghij" + truncatedMark},
+		// "日"为3字节，截断位置落在字符中间时向前退到字符边界
+		{"ab日本", 3, "ab" + truncatedMark},
+		{"ab日本", 4, "ab" + truncatedMark},
+		{"ab日本", 5, "ab日" + truncatedMark},
+		{"ab日本", 8, "ab日本"},
+		{"😀x", 2, truncatedMark},
+	}
+	for _, tt := range tests {
+		got := truncateMsg(tt.msg, tt.n)
+		if got != tt.want {
+			t.Errorf("truncateMsg(%q, %d) = %q, want %q", tt.msg, tt.n, got, tt.want)
+		}
+		if !utf8.ValidString(got) {
+			t.Errorf("truncateMsg(%q, %d) split a rune: %q", tt.msg, tt.n, got)
+		}
+	}
+}
+
+func TestMaxLineLengthKeepsPrefix(t *testing.T) {
+	l, buf := newTestLogger(t)
+	l.SetMaxLineLength(4)
+	l.With(Fields{"k": "long value"}).Info("héllo")
+	l.Flush()
+	// 只截断消息，前缀和字段不计入
+	if got := buf.String(); got != "[INFO   ] k=\"long value\" hél"+truncatedMark+"\n" {
+		t.Fatalf("got %q", got)
+	}
+}
@@ -0,0 +1,204 @@
+package MyLog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// 日志输出格式
+type LogFormat uint8
+
+const (
+	FORMAT_TEXT   LogFormat = iota // 文本格式，按flags组合前缀
+	FORMAT_JSON                    // JSON格式，每条日志为一个JSON对象
+	FORMAT_LOGFMT                  // logfmt格式，每个字段为key=value，含空格等字符的值加引号
+)
+
+// 设置日志输出格式
+func (l *Logger) SetFormat(format LogFormat) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.format = format
+}
+
+// 同Logger.SetFormat，作用于默认日志对象
+func SetFormat(format LogFormat) {
+	logger.SetFormat(format)
+}
+
+// 设置文本格式前缀各部分之间的分隔符，默认为空格
+func (l *Logger) SetFieldSeparator(sep string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.separator = sep
+}
+
+// 同Logger.SetFieldSeparator，作用于默认日志对象
+func SetFieldSeparator(sep string) {
+	logger.SetFieldSeparator(sep)
+}
+
+// 设置文本格式前缀各部分的括号，默认为[和]，均传空字符串则不加括号
+func (l *Logger) SetBracket(open, close string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.bracketOpen = open
+	l.bracketClose = close
+}
+
+// 同Logger.SetBracket，作用于默认日志对象
+func SetBracket(open, close string) {
+	logger.SetBracket(open, close)
+}
+
+// 设置每行日志开头的固定前缀，如服务名、主机名，不受flags控制，JSON格式下输出为prefix字段
+func (l *Logger) SetPrefix(prefix string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.prefix = prefix
+}
+
+// 同Logger.SetPrefix，作用于默认日志对象
+func SetPrefix(prefix string) {
+	logger.SetPrefix(prefix)
+}
+
+// 文本格式下多行消息的处理方式
+type MultilineMode uint8
+
+const (
+	MULTILINE_RAW    MultilineMode = iota // 原样输出，前缀只出现在第一行
+	MULTILINE_PREFIX                      // 每一行都输出前缀
+	MULTILINE_ESCAPE                      // 将换行转义为\n，整条消息保持在一行
+)
+
+// 将换行转义为可见字符
+var newlineEscaper = strings.NewReplacer("\r", `\r`, "\n", `\n`)
+
+// 设置文本格式下多行消息的处理方式，默认原样输出，JSON格式的换行始终会被转义
+func (l *Logger) SetMultiline(mode MultilineMode) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.multiline = mode
+}
+
+// 同Logger.SetMultiline，作用于默认日志对象
+func SetMultiline(mode MultilineMode) {
+	logger.SetMultiline(mode)
+}
+
+// 按输出格式生成完整的一行日志（不含换行），color仅对文本格式生效
+func (l *Logger) formatLine(log logMsg, color bool) string {
+	switch l.format {
+	case FORMAT_JSON:
+		return l.formatJSON(log)
+	case FORMAT_LOGFMT:
+		return l.formatLogfmt(log)
+	}
+	var stack string
+	if log.stack != "" {
+		stack = "\n" + log.stack
+	}
+	switch l.multiline {
+	case MULTILINE_ESCAPE:
+		log.msg = newlineEscaper.Replace(log.msg)
+	case MULTILINE_PREFIX:
+		if strings.ContainsAny(log.msg, "\r\n") {
+			// 每个物理行都按完整格式输出前缀
+			lines := strings.Split(strings.ReplaceAll(log.msg, "\r\n", "\n"), "\n")
+			for i, line := range lines {
+				log.msg = line
+				lines[i] = l.formatText(log, color)
+			}
+			return strings.Join(lines, "\n") + stack
+		}
+	}
+	return l.formatText(log, color) + stack
+}
+
+// 生成文本格式的一行日志
+func (l *Logger) formatText(log logMsg, color bool) string {
+	var prefix string
+	if l.prefix != "" {
+		prefix = l.prefix + l.separator
+	}
+	if l.template != nil {
+		return prefix + l.formatTemplate(l.template, log, color)
+	}
+	return prefix + l.formatPrefix(log, color) + formatFields(log.fields) + log.msg
+}
+
+// 生成JSON格式日志，字段是否输出同样由flags控制，msg字段始终输出
+func (l *Logger) formatJSON(log logMsg) string {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	l.structuredFields(log, func(key string, value interface{}) {
+		if buf.Len() > 1 {
+			buf.WriteByte(',')
+		}
+		k, _ := json.Marshal(key)
+		v, err := json.Marshal(value)
+		if err != nil {
+			v, _ = json.Marshal(err.Error())
+		}
+		buf.Write(k)
+		buf.WriteByte(':')
+		buf.Write(v)
+	})
+	buf.WriteByte('}')
+	return buf.String()
+}
+
+// 生成logfmt格式日志，如time=... level=INFO msg="hello world"，字段与JSON格式相同
+func (l *Logger) formatLogfmt(log logMsg) string {
+	var b strings.Builder
+	l.structuredFields(log, func(key string, value interface{}) {
+		if b.Len() > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(key)
+		b.WriteByte('=')
+		b.WriteString(quoteValue(fmt.Sprint(value)))
+	})
+	return b.String()
+}
+
+// 按固定顺序遍历结构化格式的各个字段，与文本格式使用同一份flags：
+// FLAG_TIME对应time，FLAG_LEVEL对应level，FLAG_THREADID对应goroutine（ID未知时省略），
+// FLAG_FILENAME、FLAG_FUNCNAME、FLAG_LINENO分别对应独立的file、func、line字段（line为数字），不合并为一个字符串；
+// prefix、logger、附加字段只在设置了时输出，msg字段始终输出，因此FLAG_NONE时只有{"msg":...}
+func (l *Logger) structuredFields(log logMsg, writeField func(key string, value interface{})) {
+	if l.prefix != "" {
+		writeField("prefix", l.prefix)
+	}
+	if log.flags&FLAG_TIME == FLAG_TIME {
+		writeField("time", log.time)
+	}
+	if log.flags&FLAG_LEVEL == FLAG_LEVEL {
+		writeField("level", strings.TrimSpace(l.levelString(log.level)))
+	}
+	if log.name != "" {
+		writeField("logger", log.name)
+	}
+	if log.flags&FLAG_THREADID == FLAG_THREADID && log.goID > 0 {
+		writeField("goroutine", log.goID)
+	}
+	if log.flags&FLAG_FILENAME == FLAG_FILENAME {
+		writeField("file", log.fileName)
+	}
+	if log.flags&FLAG_FUNCNAME == FLAG_FUNCNAME {
+		writeField("func", log.funcName)
+	}
+	if log.flags&FLAG_LINENO == FLAG_LINENO {
+		writeField("line", log.lineNo)
+	}
+	for _, f := range log.fields {
+		writeField(f.key, f.value)
+	}
+	writeField("msg", log.msg)
+	if log.stack != "" {
+		writeField("stack", log.stack)
+	}
+}
@@ -0,0 +1,304 @@
+package MyLog
+
+import (
+	"encoding/json"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// 解析一行JSON日志
+func decodeJSON(t *testing.T, line string) map[string]interface{} {
+	t.Helper()
+	var m map[string]interface{}
+	if err := json.Unmarshal([]byte(line), &m); err != nil {
+		t.Fatalf("invalid JSON %q: %v", line, err)
+	}
+	return m
+}
+
+func TestJSONFields(t *testing.T) {
+	l, buf := newTestLogger(t, WithJSON(), WithFlags(FLAG_ALL))
+	_, _, line, _ := runtime.Caller(0)
+	l.Info("say \"hi\"\n\tand <bye>")
+	l.Flush()
+	m := decodeJSON(t, strings.TrimSuffix(buf.String(), "\n"))
+	if m["time"] == "" || m["time"] == nil {
+		t.Fatalf("missing time: %v", m)
+	}
+	checks := map[string]interface{}{
+		"level": "INFO",
+		"file":  "format_test.go",
+		"func":  "TestJSONFields",
+		"line":  float64(line + 1),
+		"msg":   "say \"hi\"\n\tand <bye>",
+	}
+	for k, want := range checks {
+		if m[k] != want {
+			t.Errorf("%s = %#v, want %#v", k, m[k], want)
+		}
+	}
+	if id, ok := m["goroutine"].(float64); !ok || id <= 0 {
+		t.Errorf("goroutine = %#v", m["goroutine"])
+	}
+}
+
+func TestJSONRespectsFlags(t *testing.T) {
+	l, buf := newTestLogger(t, WithJSON(), WithFlags(FLAG_LEVEL|FLAG_LINENO))
+	l.Warning("w")
+	l.SetFlags(FLAG_NONE)
+	l.Warning("none")
+	l.Flush()
+	got := lines(buf)
+	m := decodeJSON(t, got[0])
+	if len(m) != 3 || m["level"] != "WARNING" || m["line"] == nil || m["msg"] != "w" {
+		t.Fatalf("got %v", m)
+	}
+	if got[1] != `{"msg":"none"}` {
+		t.Fatalf("FLAG_NONE gave %q", got[1])
+	}
+}
+
+func TestSubSecondPrecision(t *testing.T) {
+	at := time.Date(2024, 6, 1, 8, 30, 15, 123456789, time.UTC)
+	tests := []struct{ layout, want string }{
+		{TIME_FORMAT_MILLI, "2024-06-01 08:30:15.123"},
+		{TIME_FORMAT_MICRO, "2024-06-01 08:30:15.123456"},
+		{TIME_FORMAT_NANO, "2024-06-01 08:30:15.123456789"},
+	}
+	for _, tt := range tests {
+		// 文本与JSON输出使用相同的精度
+		l, buf := newTestLogger(t, WithFlags(FLAG_TIME))
+		l.SetClock(func() time.Time { return at })
+		l.SetTimeFormat(tt.layout)
+		l.Info("text")
+		l.Flush()
+		if got := buf.String(); got != "["+tt.want+"] text\n" {
+			t.Errorf("text got %q", got)
+		}
+		buf.Reset()
+		l.SetFormat(FORMAT_JSON)
+		l.Info("json")
+		l.Flush()
+		if m := decodeJSON(t, strings.TrimSuffix(buf.String(), "\n")); m["time"] != tt.want {
+			t.Errorf("json time = %#v, want %q", m["time"], tt.want)
+		}
+	}
+}
+
+func TestSeparatorAndBrackets(t *testing.T) {
+	tests := []struct {
+		flags       LogFlag
+		sep         string
+		open, close string
+		want        string
+	}{
+		{FLAG_LEVEL | FLAG_LINENO, " | ", "", "", "INFO    | lineN | msg"},
+		{FLAG_LEVEL | FLAG_FILENAME, "|", "<", ">", "<INFO   >|<format_test.go>|msg"},
+		{FLAG_LEVEL | FLAG_FILENAME | FLAG_FUNCNAME | FLAG_LINENO, " ", "(", ")", "(INFO   ) (format_test.go TestSeparatorAndBrackets() lineN) msg"},
+		{FLAG_NONE, " | ", "", "", "msg"},
+	}
+	for _, tt := range tests {
+		l, buf := newTestLogger(t, WithFlags(tt.flags))
+		l.SetFieldSeparator(tt.sep)
+		l.SetBracket(tt.open, tt.close)
+		_, _, line, _ := runtime.Caller(0)
+		l.Info("msg")
+		l.Flush()
+		want := strings.Replace(tt.want, "lineN", "line"+strconv.Itoa(line+1), 1)
+		if got := strings.TrimSuffix(buf.String(), "\n"); got != want {
+			t.Errorf("flags %08b: got %q, want %q", tt.flags, got, want)
+		}
+	}
+}
+
+func TestPrefixTextAndJSON(t *testing.T) {
+	skipIfDebugStripped(t)
+	l, buf := newTestLogger(t)
+	l.SetPrefix("host1")
+	l.Debug("d")
+	l.Warning("w")
+	l.Error("e")
+	l.Flush()
+	// 前缀出现在所有等级、按flags组合的前缀之前
+	if got := buf.String(); got != "host1 [DEBUG  ] d\nhost1 [WARNING] w\nhost1 [ERROR  ] e\n" {
+		t.Fatalf("got %q", got)
+	}
+	buf.Reset()
+	l.SetFormat(FORMAT_JSON)
+	l.Info("j")
+	l.Flush()
+	if m := decodeJSON(t, strings.TrimSuffix(buf.String(), "\n")); m["prefix"] != "host1" || m["msg"] != "j" {
+		t.Fatalf("got %v", m)
+	}
+}
+
+func TestMultilineModes(t *testing.T) {
+	tests := []struct {
+		mode MultilineMode
+		msg  string
+		want string
+	}{
+		{MULTILINE_RAW, "a: 1\nb: 2", "[INFO   ] a: 1\nb: 2\n"},
+		{MULTILINE_PREFIX, "a: 1\nb: 2", "[INFO   ] a: 1\n[INFO   ] b: 2\n"},
+		{MULTILINE_PREFIX, "a: 1\r\nb: 2", "[INFO   ] a: 1\n[INFO   ] b: 2\n"},
+		{MULTILINE_ESCAPE, "a: 1\r\nb: 2", "[INFO   ] a: 1\\r\\nb: 2\n"},
+	}
+	for _, tt := range tests {
+		l, buf := newTestLogger(t)
+		l.SetMultiline(tt.mode)
+		l.Info(tt.msg)
+		l.Flush()
+		if got := buf.String(); got != tt.want {
+			t.Errorf("mode %d, msg %q: got %q, want %q", tt.mode, tt.msg, got, tt.want)
+		}
+	}
+}
+
+// 解析一行logfmt日志，带引号的值按Go字符串字面量解析
+func decodeLogfmt(t *testing.T, line string) map[string]string {
+	t.Helper()
+	m := map[string]string{}
+	for line != "" {
+		eq := strings.IndexByte(line, '=')
+		if eq <= 0 {
+			t.Fatalf("invalid logfmt %q", line)
+		}
+		key := line[:eq]
+		line = line[eq+1:]
+		var value string
+		if strings.HasPrefix(line, `"`) {
+			quoted, err := strconv.QuotedPrefix(line)
+			if err != nil {
+				t.Fatalf("invalid quoted value %q: %v", line, err)
+			}
+			value, _ = strconv.Unquote(quoted)
+			line = line[len(quoted):]
+		} else {
+			end := strings.IndexByte(line, ' ')
+			if end < 0 {
+				end = len(line)
+			}
+			value = line[:end]
+			line = line[end:]
+		}
+		m[key] = value
+		line = strings.TrimPrefix(line, " ")
+	}
+	return m
+}
+
+func TestLogfmtRoundTrip(t *testing.T) {
+	l, buf := newTestLogger(t, WithFormat(FORMAT_LOGFMT), WithFlags(FLAG_LEVEL|FLAG_LINENO))
+	l.With(Fields{"empty": "", "eq": "a=b", "plain": "x"}).Info("say \"hi\"\tnow")
+	l.Flush()
+	line := strings.TrimSuffix(buf.String(), "\n")
+	m := decodeLogfmt(t, line)
+	want := map[string]string{
+		"level": "INFO",
+		"empty": "",
+		"eq":    "a=b",
+		"plain": "x",
+		"msg":   "say \"hi\"\tnow",
+	}
+	for k, v := range want {
+		if m[k] != v {
+			t.Errorf("%s = %q, want %q", k, m[k], v)
+		}
+	}
+	if _, err := strconv.Atoi(m["line"]); err != nil {
+		t.Errorf("line = %q", m["line"])
+	}
+	// 只有需要时才加引号
+	if !strings.Contains(line, " plain=x ") || !strings.Contains(line, ` empty="" `) {
+		t.Errorf("unexpected quoting in %q", line)
+	}
+
+	// 不包含flags未开启的字段
+	buf.Reset()
+	l.SetFlags(FLAG_NONE)
+	l.Info("bare")
+	l.Flush()
+	if got := buf.String(); got != "msg=bare\n" {
+		t.Fatalf("got %q", got)
+	}
+}
+
+func TestLevelPadding(t *testing.T) {
+	l, buf := newTestLogger(t)
+	l.Info("aligned")
+	l.Flush()
+	l.SetLevelPadding(false)
+	l.Info("trimmed")
+	l.Flush()
+	// 文本格式默认保留对齐空格，关闭后去掉
+	if got := buf.String(); got != "[INFO   ] aligned\n[INFO] trimmed\n" {
+		t.Fatalf("text got %q", got)
+	}
+
+	// 结构化格式始终去掉对齐空格
+	l.SetLevelPadding(true)
+	for _, format := range []LogFormat{FORMAT_JSON, FORMAT_LOGFMT} {
+		buf.Reset()
+		l.SetFormat(format)
+		l.Info("s")
+		l.Flush()
+		if got := buf.String(); !strings.Contains(got, `"level":"INFO"`) && !strings.Contains(got, "level=INFO ") {
+			t.Errorf("format %d got %q", format, got)
+		}
+	}
+}
+
+func TestJSONFlagCombinations(t *testing.T) {
+	cases := []struct {
+		flags LogFlag
+		keys  []string
+	}{
+		{FLAG_NONE, []string{"msg"}},
+		{FLAG_TIME, []string{"time", "msg"}},
+		{FLAG_TIME | FLAG_LEVEL, []string{"time", "level", "msg"}},
+		{FLAG_FILENAME | FLAG_LINENO, []string{"file", "line", "msg"}},
+		{FLAG_FUNCNAME, []string{"func", "msg"}},
+		{FLAG_THREADID | FLAG_LEVEL, []string{"level", "goroutine", "msg"}},
+		{FLAG_ALL, []string{"time", "level", "goroutine", "file", "func", "line", "msg"}},
+	}
+	for _, c := range cases {
+		l, buf := newTestLogger(t, WithJSON(), WithFlags(c.flags))
+		l.Info("m")
+		l.Flush()
+		m := decodeJSON(t, strings.TrimSuffix(buf.String(), "\n"))
+		if len(m) != len(c.keys) {
+			t.Errorf("flags %06b: got %v, want keys %v", c.flags, m, c.keys)
+			continue
+		}
+		for _, k := range c.keys {
+			if _, ok := m[k]; !ok {
+				t.Errorf("flags %06b: missing %q in %v", c.flags, k, m)
+			}
+		}
+	}
+}
+
+func TestJSONCallerSeparateFields(t *testing.T) {
+	l, buf := newTestLogger(t, WithJSON(), WithFlags(FLAG_FILENAME|FLAG_FUNCNAME|FLAG_LINENO))
+	_, _, line, _ := runtime.Caller(0)
+	l.Info("m")
+	l.Flush()
+	got := strings.TrimSuffix(buf.String(), "\n")
+	// line必须是数字而非字符串，且不存在合并的caller字段
+	if !strings.Contains(got, `"line":`+strconv.Itoa(line+1)+`,`) {
+		t.Fatalf("line is not a JSON number: %s", got)
+	}
+	m := decodeJSON(t, got)
+	if _, ok := m["line"].(float64); !ok {
+		t.Fatalf("line = %#v", m["line"])
+	}
+	if _, ok := m["caller"]; ok {
+		t.Fatalf("unexpected caller field: %s", got)
+	}
+	if m["file"] != "format_test.go" || m["func"] != "TestJSONCallerSeparateFields" {
+		t.Fatalf("got %v", m)
+	}
+}
@@ -0,0 +1,3 @@
+module github.com/YuyangHou1230/MyLog-go
+
+go 1.21
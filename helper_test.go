@@ -0,0 +1,204 @@
+package MyLog
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMain(m *testing.M) {
+	// 默认日志对象不写文件、不输出到终端，避免测试在源码目录中留下日志文件
+	logger.SetOutputType(ONLY_TERMINAL)
+	logger.SetOutput(io.Discard)
+	logger.SetErrorOutput(io.Discard)
+	os.Exit(m.Run())
+}
+
+// 创建只输出到内存缓冲的日志对象，默认仅输出等级标识，测试结束时关闭
+// 缓冲由输出协程写入，需在Flush或Close之后读取
+func newTestLogger(t testing.TB, opts ...Option) (*Logger, *bytes.Buffer) {
+	t.Helper()
+	var buf bytes.Buffer
+	base := []Option{
+		WithOutputType(ONLY_TERMINAL),
+		WithOutput(&buf),
+		WithErrorToStderr(false),
+		WithFlags(FLAG_LEVEL),
+	}
+	l := New(append(base, opts...)...)
+	t.Cleanup(func() { l.Close() })
+	return l, &buf
+}
+
+// 按行拆分输出内容，去掉末尾的空行
+func lines(buf *bytes.Buffer) []string {
+	s := strings.TrimSuffix(buf.String(), "\n")
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, "\n")
+}
+
+// 并发安全的缓冲，用于在输出协程写入期间读取
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// 替换进程退出函数，返回记录的退出码，未调用时为-1
+func stubExit(t testing.TB) *int {
+	t.Helper()
+	code := -1
+	old := exit
+	exit = func(c int) { code = c }
+	t.Cleanup(func() { exit = old })
+	return &code
+}
+
+// 将默认日志对象的输出改为内存缓冲，默认仅输出等级标识，测试结束时恢复
+func captureDefault(t testing.TB) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	level := logger.GetLevel()
+	logger.SetOutput(&buf)
+	logger.SetErrorOutput(&buf)
+	logger.SetFlags(FLAG_LEVEL)
+	t.Cleanup(func() {
+		logger.Flush()
+		logger.SetOutput(io.Discard)
+		logger.SetErrorOutput(io.Discard)
+		logger.SetFlags(FLAG_ALL)
+		logger.SetLevel(level)
+	})
+	return &buf
+}
+
+// 写入时阻塞直到release关闭的输出目标，每次进入Write时向entered发送信号
+type gateWriter struct {
+	entered chan struct{}
+	release chan struct{}
+	buf     syncBuffer
+}
+
+func newGateWriter() *gateWriter {
+	return &gateWriter{entered: make(chan struct{}, 100), release: make(chan struct{})}
+}
+
+func (w *gateWriter) Write(p []byte) (int, error) {
+	select {
+	case w.entered <- struct{}{}:
+	default:
+	}
+	<-w.release
+	return w.buf.Write(p)
+}
+
+// 可手动推进的时钟，用于SetClock
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func newFakeClock(now time.Time) *fakeClock {
+	return &fakeClock{now: now}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Add(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// 记录每次Write调用内容的输出目标
+type recordWriter struct {
+	mu     sync.Mutex
+	writes []string
+}
+
+func (w *recordWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.writes = append(w.writes, string(p))
+	return len(p), nil
+}
+
+func (w *recordWriter) Writes() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return append([]string(nil), w.writes...)
+}
+
+// 收集OnError上报的错误
+type errorSink struct {
+	mu   sync.Mutex
+	errs []error
+}
+
+func (s *errorSink) report(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.errs = append(s.errs, err)
+}
+
+func (s *errorSink) Errors() []error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]error(nil), s.errs...)
+}
+
+// 写入总是失败的输出目标
+type failWriter struct{}
+
+func (failWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("device full")
+}
+
+// 测试期间将标准错误替换为临时文件，返回读取已写入内容的函数
+func captureStderr(t *testing.T) func() string {
+	t.Helper()
+	f, err := os.Create(t.TempDir() + "/stderr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stderr := os.Stderr
+	os.Stderr = f
+	t.Cleanup(func() {
+		os.Stderr = stderr
+		f.Close()
+	})
+	return func() string {
+		data, _ := os.ReadFile(f.Name())
+		return string(data)
+	}
+}
+
+// 使用mylog_nodebug构建时跳过依赖TRACE、DEBUG输出的测试
+func skipIfDebugStripped(t *testing.T) {
+	t.Helper()
+	if debugStripped {
+		t.Skip("TRACE and DEBUG are stripped by mylog_nodebug")
+	}
+}
@@ -0,0 +1,32 @@
+package MyLog
+
+// 日志钩子
+type hook struct {
+	level LevelLog                         // 触发钩子的最低等级
+	fn    func(level LevelLog, msg string) // 钩子函数
+}
+
+// 添加钩子，等级不低于level的日志放入通道后调用fn
+// 钩子在调用日志函数的协程中同步执行，耗时操作请在fn内自行启动协程；在钩子中输出同等级日志会导致递归
+func (l *Logger) AddHook(level LevelLog, fn func(level LevelLog, msg string)) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	// 复制一份新切片，避免与正在执行的钩子列表冲突
+	hooks := make([]hook, 0, len(l.hooks)+1)
+	hooks = append(hooks, l.hooks...)
+	l.hooks = append(hooks, hook{level: level, fn: fn})
+}
+
+// 同Logger.AddHook，作用于默认日志对象
+func AddHook(level LevelLog, fn func(level LevelLog, msg string)) {
+	logger.AddHook(level, fn)
+}
+
+// 执行满足等级的钩子
+func runHooks(hooks []hook, level LevelLog, msg string) {
+	for _, h := range hooks {
+		if level >= h.level {
+			h.fn(level, msg)
+		}
+	}
+}
@@ -0,0 +1,23 @@
+package MyLog
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHookFiresAtOrAboveLevel(t *testing.T) {
+	stubExit(t)
+	l, _ := newTestLogger(t)
+	var fired []string
+	l.AddHook(ERROR, func(level LevelLog, msg string) {
+		fired = append(fired, level.String()+":"+msg)
+	})
+	l.Info("info")
+	l.Warning("warning")
+	l.Error("error")
+	l.Fatalf("fatal %d", 1)
+	// 钩子在调用方协程中同步执行，返回时已调用
+	if got := strings.Join(fired, ","); got != "ERROR:error,FATAL:fatal 1" {
+		t.Fatalf("hooks fired for %q", got)
+	}
+}
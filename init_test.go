@@ -0,0 +1,25 @@
+package MyLog_test
+
+import (
+	"bytes"
+	"testing"
+
+	MyLog "github.com/YuyangHou1230/MyLog-go"
+)
+
+// 在其他包的init中使用默认日志对象时，MyLog已完成初始化
+var initOutput bytes.Buffer
+
+func init() {
+	MyLog.SetOutputType(MyLog.ONLY_TERMINAL)
+	MyLog.SetOutput(&initOutput)
+	MyLog.SetFlags(MyLog.FLAG_LEVEL)
+	MyLog.Info("from init")
+	MyLog.Flush()
+}
+
+func TestLogFromInit(t *testing.T) {
+	if got := initOutput.String(); got != "[INFO   ] from init\n" {
+		t.Fatalf("got %q", got)
+	}
+}
@@ -0,0 +1,28 @@
+package MyLog
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// 将v序列化为JSON作为消息内容，序列化失败时改用fmt.Sprint并附加错误说明
+// 输出为紧凑的单行JSON，便于按行解析
+func jsonMsg(v interface{}) lazyMsg {
+	return func() string {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprintf("%s (json: %v)", fmt.Sprint(v), err)
+		}
+		return string(data)
+	}
+}
+
+// 以JSON形式输出结构化值的信息，仅在该等级需要输出时才序列化
+func (l *Logger) InfoJSON(v interface{}) {
+	l.handleLogMsg(INFO, jsonMsg(v))
+}
+
+// 同Logger.InfoJSON，作用于默认日志对象
+func InfoJSON(v interface{}) {
+	logger.handleLogMsg(INFO, jsonMsg(v))
+}
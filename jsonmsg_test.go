@@ -0,0 +1,61 @@
+package MyLog
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type jsonAddress struct {
+	City string   `json:"city"`
+	Tags []string `json:"tags"`
+}
+
+type jsonUser struct {
+	Name    string       `json:"name"`
+	Age     int          `json:"age"`
+	Address *jsonAddress `json:"address"`
+}
+
+func TestInfoJSONRoundTrip(t *testing.T) {
+	l, buf := newTestLogger(t, WithFlags(FLAG_NONE))
+	want := jsonUser{Name: "bob", Age: 30, Address: &jsonAddress{City: "x", Tags: []string{"a", "b"}}}
+	l.InfoJSON(want)
+	l.Flush()
+	var got jsonUser
+	if err := json.Unmarshal([]byte(strings.TrimSuffix(buf.String(), "\n")), &got); err != nil {
+		t.Fatalf("message %q is not JSON: %v", buf.String(), err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestInfoJSONMarshalError(t *testing.T) {
+	l, buf := newTestLogger(t, WithFlags(FLAG_NONE))
+	l.InfoJSON(map[string]interface{}{"c": make(chan int)})
+	l.Flush()
+	got := buf.String()
+	if !strings.HasPrefix(got, "map[c:0x") || !strings.Contains(got, "(json: json: unsupported type: chan int)") {
+		t.Fatalf("got %q", got)
+	}
+}
+
+// 计数被序列化的次数
+type countingMarshaler struct{ calls *int }
+
+func (m countingMarshaler) MarshalJSON() ([]byte, error) {
+	*m.calls++
+	return []byte(`{}`), nil
+}
+
+func TestInfoJSONLazy(t *testing.T) {
+	l, buf := newTestLogger(t, WithLevel(WARNING))
+	var calls int
+	l.InfoJSON(countingMarshaler{&calls})
+	l.Flush()
+	if calls != 0 || buf.Len() != 0 {
+		t.Fatalf("filtered InfoJSON marshaled %d times, output %q", calls, buf.String())
+	}
+}
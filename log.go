@@ -1,34 +1,96 @@
 package MyLog
 
 import (
+	"bufio"
+	"context"
 	"fmt"
+	"io"
 	"os"
-	"path"
+	"os/signal"
 	"runtime"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
-// 日志等级
+// 日志等级，数值越大越严重，等级过滤按数值比较
 type LevelLog uint8
 
 const (
-	DEBUG LevelLog = iota
+	TRACE LevelLog = iota
+	DEBUG
 	INFO
 	WARNING
 	ERROR
 	FATAL
 )
 
+// 日志等级的规范名称
+var levelNames = [...]string{
+	TRACE:   "TRACE",
+	DEBUG:   "DEBUG",
+	INFO:    "INFO",
+	WARNING: "WARNING",
+	ERROR:   "ERROR",
+	FATAL:   "FATAL",
+}
+
+// 返回日志等级的规范名称，如INFO，与SetLevelString设置的显示标识无关
+func (level LevelLog) String() string {
+	if int(level) < len(levelNames) {
+		return levelNames[level]
+	}
+	return fmt.Sprintf("LEVEL%d", level)
+}
+
+// 按名称解析日志等级，不区分大小写，WARN可作为WARNING的简写
+func ParseLevel(name string) (LevelLog, error) {
+	upper := strings.ToUpper(strings.TrimSpace(name))
+	if upper == "WARN" {
+		return WARNING, nil
+	}
+	for level, levelName := range levelNames {
+		if upper == levelName {
+			return LevelLog(level), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown log level: %q", name)
+}
+
 // 输出类型
 type OutputType uint8
 
 const (
-	ONLY_TERMINAL          OutputType                  = iota // 输出到终端
-	ONLY_FILE                                                 // 输出到文件
-	BOTH_TERMINAL_AND_FILE = ONLY_TERMINAL | ONLY_FILE        // 既输出到终端也输出到文件
+	ONLY_TERMINAL          OutputType                  = 1 << iota // 输出到终端
+	ONLY_FILE                                                      // 输出到文件
+	BOTH_TERMINAL_AND_FILE = ONLY_TERMINAL | ONLY_FILE             // 既输出到终端也输出到文件
+)
+
+// 内置的时间格式，可传给SetTimeFormat，文本和JSON格式均使用该精度
+const (
+	TIME_FORMAT_SECOND = "2006-01-02 15:04:05"           // 秒，默认格式
+	TIME_FORMAT_MILLI  = "2006-01-02 15:04:05.000"       // 毫秒
+	TIME_FORMAT_MICRO  = "2006-01-02 15:04:05.000000"    // 微秒
+	TIME_FORMAT_NANO   = "2006-01-02 15:04:05.000000000" // 纳秒
+)
+
+// 时间字段的记录方式
+type TimeMode uint8
+
+const (
+	TIME_WALL    TimeMode = iota // 按时间格式输出当前时间，默认方式
+	TIME_ELAPSED                 // 输出自日志对象创建以来经过的时间，如+0.0123s
+)
+
+// 通道满时的处理策略
+type OverflowPolicy uint8
+
+const (
+	OVERFLOW_BLOCK       OverflowPolicy = iota // 阻塞等待通道有空位
+	OVERFLOW_DROP                              // 丢弃新消息
+	OVERFLOW_DROP_OLDEST                       // 丢弃通道中最旧的消息
 )
 
 // 日志输出字段定制
@@ -38,11 +100,11 @@ const (
 	FLAG_NONE     LogFlag = 0b00000000 // 无前缀标识
 	FLAG_TIME     LogFlag = 0b00000001 // 有时间标识
 	FLAG_THREADID LogFlag = 0b00000010 // 有线程ID标识
-	FLAG_LEVEL    LogFlag = 0b00000010 // 有等级标识
-	FLAG_FILENAME LogFlag = 0b00000100 // 有文件名标识
-	FLAG_FUNCNAME LogFlag = 0b00001000 // 有函数名标识
-	FLAG_LINENO   LogFlag = 0b00010000 // 有行号标识
-	FLAG_ALL      LogFlag = 0b00011111 // 上述标识均有
+	FLAG_LEVEL    LogFlag = 0b00000100 // 有等级标识
+	FLAG_FILENAME LogFlag = 0b00001000 // 有文件名标识
+	FLAG_FUNCNAME LogFlag = 0b00010000 // 有函数名标识
+	FLAG_LINENO   LogFlag = 0b00100000 // 有行号标识
+	FLAG_ALL      LogFlag = 0b00111111 // 上述标识均有
 )
 
 // 单条日志信息结构体
@@ -54,247 +116,1683 @@ type logMsg struct {
 	funcName string
 	lineNo   int
 	goID     int
+	at       time.Time     // 日志产生的时间
+	fields   []field       // 附加的键值对字段
+	name     string        // 日志对象的组件名称
+	flags    LogFlag       // 输出字段定义，提交消息时确定
+	stack    string        // 调用栈，未开启时为空
+	flushed  chan struct{} // 非空时表示刷新请求，输出协程处理到此处时关闭该通道
+	batch    []*logMsg     // 非空时为批量提交的多条消息，按顺序输出
+	control  func()        // 非空时为需在输出协程中执行的设置操作，执行时持有写锁，flushed同时非空
+}
+
+// 按日志等级区分处理的输出目标，如syslog
+type leveledSink interface {
+	writeLevel(level LevelLog, content string) error
+	Close() error
 }
 
-// 日志对象结构体
+// 日志对象结构体，导出字段请通过Set系列函数修改，直接赋值不是并发安全的
 type Logger struct {
-	Level      LevelLog            // 日志等级
-	LevelStr   map[LevelLog]string // 日志标识map
-	OutputType OutputType          // 输出类型
-	Flags      LogFlag             // 输出字段定义
-	fileName   string              // 文件名
-	filePath   string              // 日志路径
-	fileObj    *os.File            // 日志对象
-	msg        chan *logMsg        // 存储日志msg的通道
-}
-
-var onceLogger sync.Once   // 实现日志单例对象
-var onceOpenFile sync.Once // 实现只打开一次文件
-var logger *Logger         // 定义单例日志指针
-
-// 获取单例Logger对象
-func getInstance() *Logger {
-	if logger == nil {
-		onceLogger.Do(func() {
-			logger = &Logger{
-				Level: DEBUG,
-				LevelStr: map[LevelLog]string{
-					DEBUG:   "DEBUG  ",
-					INFO:    "INFO   ",
-					WARNING: "WARNING",
-					ERROR:   "ERROR  ",
-					FATAL:   "FATAL  ",
-				},
-				OutputType: BOTH_TERMINAL_AND_FILE,
-				Flags:      FLAG_ALL,
-				fileName:   time.Now().Format("20060102") + "_test.log",
-				msg:        make(chan *logMsg, 1000),
-			}
-		})
-	}
-	return logger
+	Level         LevelLog                                // 日志等级
+	LevelStr      map[LevelLog]string                     // 日志标识map，只读，修改请使用SetLevelString
+	OutputType    OutputType                              // 输出类型
+	terminalLevel LevelLog                                // 终端输出的最低等级，低于Level时以Level为准
+	fileLevel     LevelLog                                // 文件输出的最低等级，低于Level时以Level为准
+	Flags         LogFlag                                 // 输出字段定义
+	format        LogFormat                               // 输出格式
+	timeFormat    string                                  // 时间格式
+	timeUTC       bool                                    // 是否使用UTC时间
+	timeMode      TimeMode                                // 时间字段的记录方式
+	start         time.Time                               // 日志对象创建的时间，ELAPSED方式以此为起点
+	clock         func() time.Time                        // 获取当前时间的函数，为空时使用time.Now
+	times         timeCache                               // 按秒缓存的时间字符串
+	separator     string                                  // 前缀各部分之间的分隔符
+	bracketOpen   string                                  // 前缀各部分的左括号
+	bracketClose  string                                  // 前缀各部分的右括号
+	template      *lineTemplate                           // 文本格式的日志行模板，为空时按flags组合
+	prefix        string                                  // 每行日志开头的固定前缀
+	multiline     MultilineMode                           // 文本格式下多行消息的处理方式
+	levelPadding  bool                                    // 文本格式下是否保留等级标识的对齐空格
+	fileName      string                                  // 文件名
+	filePath      string                                  // 日志路径
+	fileObj       *os.File                                // 日志对象
+	fileBuf       *bufio.Writer                           // 文件写入缓冲
+	flushInterval time.Duration                           // 文件缓冲的刷新间隔
+	flushC        <-chan time.Time                        // 文件缓冲的定时刷新信号
+	fileSize      int64                                   // 当前日志文件已写入的字节数
+	maxFileSize   int64                                   // 单个日志文件最大字节数
+	maxBackups    int                                     // 滚动后保留的备份数量
+	compress      bool                                    // 是否压缩滚动后的备份
+	compressWG    sync.WaitGroup                          // 等待后台压缩完成
+	rotation      RotationInterval                        // 按时间滚动的周期
+	period        string                                  // 当前文件所属的时间周期
+	errFile       *Logger                                 // 单独记录WARNING及以上等级的错误日志文件
+	terminal      io.Writer                               // 终端输出目标，默认为标准输出
+	errTerminal   io.Writer                               // 错误输出目标，默认为标准错误
+	errorToStderr bool                                    // WARNING及以上等级是否输出到错误输出
+	color         bool                                    // 标准输出目标是否着色
+	errColor      bool                                    // 错误输出目标是否着色
+	colorForced   bool                                    // 是否由SetColor强制指定着色，否则按输出目标是否为终端自动判断
+	writers       []io.Writer                             // 额外注册的输出目标
+	sinks         []*asyncSink                            // 通过AddAsyncWriter添加、在独立协程中写入的输出目标
+	hooks         []hook                                  // 日志钩子
+	msgFilter     func(level LevelLog, msg string) string // 输出前处理消息内容的过滤函数
+	maxLineLength int                                     // 消息的最大字节数，超出部分被截断，0表示不限制
+	name          string                                  // 组件名称，由Named设置
+	fields        []field                                 // 每条日志附带的固定字段，由With设置
+	root          *Logger                                 // Named创建的子对象指向负责输出的根对象
+	syslog        leveledSink                             // 系统日志输出目标
+	remote        *remoteSink                             // 远程日志服务输出目标
+	ring          *ringBuffer                             // 保存最近日志的环形缓冲
+	sampling      int                                     // 采样间隔，连续相同的消息每sampling条输出一条
+	sampleLevel   LevelLog                                // 采样中的消息等级
+	sampleMsg     string                                  // 采样中的消息内容
+	sampleCount   int                                     // 采样中的消息已连续出现的次数
+	sampleSkipped int                                     // 上次输出之后被省略的条数
+	sampleLast    *logMsg                                 // 最近一条被省略的消息
+	dedup         bool                                    // 是否合并连续重复的消息
+	dedupMsg      *logMsg                                 // 最近一条输出的消息，用于判断重复
+	dedupCount    int                                     // 最近一条消息之后被合并的重复次数
+	dedupLast     *logMsg                                 // 最近一条被合并的重复消息
+	mu            sync.RWMutex                            // 保护可变配置
+	msg           chan *logMsg                            // 存储日志msg的通道
+	overflow      OverflowPolicy                          // 通道满时的处理策略
+	dropped       uint64                                  // 被丢弃的消息数，原子操作
+	levelCounts   [FATAL + 1]uint64                       // 各等级已输出的消息数，原子操作
+	disabled      int32                                   // 非0时不输出任何日志，原子操作
+	syncMode      int32                                   // 非0时在调用日志函数的协程中直接输出，原子操作
+	errorChain    int32                                   // 非0时展开ERROR、FATAL日志参数中的error，原子操作
+	writeMu       sync.Mutex                              // 串行化各目标的写入
+	callerSkip    int                                     // 获取调用信息时额外跳过的栈帧数
+	caller        bool                                    // 是否获取调用信息，关闭时忽略文件名、函数名、行号标识
+	pathSegments  int                                     // 文件名保留的路径段数，0表示完整路径
+	stackLevel    LevelLog                                // 该等级及以上的日志附带调用栈
+	failLevel     LevelLog                                // 该等级及以上的日志输出过时ShouldFail返回true
+	fileOpened    bool                                    // 是否已尝试打开文件
+	fileFallback  bool                                    // 文件不可用时是否已提示改为写入标准错误
+	fileMode      os.FileMode                             // 日志文件的权限，0表示使用默认权限
+	onError       func(err error)                         // 输出出错时的回调
+	errMu         sync.Mutex                              // 保护onError，上报错误时不获取mu，避免后台协程与等待它的输出协程死锁
+	quit          chan struct{}                           // 关闭信号
+	done          chan struct{}                           // 输出协程退出后关闭
+	closeOnce     sync.Once                               // 保证只关闭一次
+	closeErr      error                                   // 关闭文件时的错误
 }
 
-func init() {
-	// 初始化Log单例
-	getInstance()
+// 默认日志对象，包级别函数均作用于该对象
+// 在包变量初始化阶段创建并启动输出协程，早于本包及导入本包的其他包的init函数，任何init中均可直接使用
+var logger = New()
 
-	// 初始化日志文件保存路径
-	curPath, err := os.Getwd()
-	if err != nil {
-		fmt.Println("get current file path failed! err:", err)
-		return
+var exit = os.Exit // 进程退出函数，Fatal输出后调用
+
+// 默认的通道容量
+const defaultBufferSize = 1000
+
+// 从getFuncCallerInfo到调用日志函数处的栈帧数：getFuncCallerInfo <- submit <- handleLogMsg <- Info等 <- 调用方
+const callerDepth = 4
+
+// 默认的日志等级标识
+func defaultLevelStr() map[LevelLog]string {
+	return map[LevelLog]string{
+		TRACE:   "TRACE  ",
+		DEBUG:   "DEBUG  ",
+		INFO:    "INFO   ",
+		WARNING: "WARNING",
+		ERROR:   "ERROR  ",
+		FATAL:   "FATAL  ",
 	}
-	logger.filePath = curPath
-	// 运行goroutine实现日志的写入打印操作
-	go outPut()
+}
+
+// 创建默认配置的日志对象，不启动输出协程
+func newLogger() *Logger {
+	l := &Logger{
+		Level:         DEBUG,
+		LevelStr:      defaultLevelStr(),
+		OutputType:    BOTH_TERMINAL_AND_FILE,
+		Flags:         FLAG_ALL,
+		timeFormat:    TIME_FORMAT_SECOND,
+		start:         time.Now(),
+		separator:     " ",
+		levelPadding:  true,
+		bracketOpen:   "[",
+		bracketClose:  "]",
+		pathSegments:  1,
+		caller:        true,
+		stackLevel:    FATAL + 1,
+		failLevel:     FATAL + 1,
+		flushInterval: 200 * time.Millisecond,
+		terminal:      os.Stdout,
+		errTerminal:   os.Stderr,
+		errorToStderr: true,
+		color:         isTerminal(os.Stdout),
+		errColor:      isTerminal(os.Stderr),
+		fileName:      time.Now().Format("20060102") + "_test.log",
+		msg:           make(chan *logMsg, defaultBufferSize),
+		quit:          make(chan struct{}),
+		done:          make(chan struct{}),
+	}
+
+	// 初始化日志文件保存路径，按平台选择默认目录
+	l.filePath = defaultLogDir()
+	return l
+}
 
-	fmt.Println("Logger init Success!")
+// 创建独立的日志对象，拥有自己的通道、文件、配置和输出协程
+func New(opts ...Option) *Logger {
+	l := newLogger()
+	for _, opt := range opts {
+		opt(l)
+	}
+	go l.outPut()
+	return l
 }
 
 // 日志输出函数
-func outPut() {
-	var content string
+func (l *Logger) outPut() {
+	// 阻塞等待通道中的消息，空闲时不占用CPU
 	for {
 		select {
-		case log := <-logger.msg:
-			content = logger.formatPrefix(*log) + log.msg
-			// 判断是否输出到终端
-			if logger.OutputType&ONLY_TERMINAL == ONLY_TERMINAL {
-				fmt.Println(content)
+		case log := <-l.msg:
+			l.safeWriteMsg(log)
+		case <-l.flushC:
+			// 定时将文件缓冲写入文件，flushC只在输出协程中读写
+			l.flushC = nil
+			l.writeMu.Lock()
+			l.mu.RLock()
+			l.flushFileBuf()
+			l.mu.RUnlock()
+			l.writeMu.Unlock()
+		case <-l.quit:
+			// 收到关闭信号，输出通道中剩余的消息后关闭文件退出
+			for {
+				select {
+				case log := <-l.msg:
+					l.safeWriteMsg(log)
+				default:
+					l.writeMu.Lock()
+					l.mu.Lock()
+					l.flushRepeat()
+					if l.syslog != nil {
+						l.syslog.Close()
+					}
+					l.closeErr = l.closeFile()
+					if l.errFile != nil {
+						if err := l.errFile.closeFile(); err != nil && l.closeErr == nil {
+							l.closeErr = err
+						}
+					}
+					errFile := l.errFile
+					remote := l.remote
+					sinks := l.sinks
+					l.mu.Unlock()
+					l.writeMu.Unlock()
+					if remote != nil {
+						remote.Close()
+					}
+					for _, s := range sinks {
+						s.Close()
+					}
+					l.compressWG.Wait()
+					if errFile != nil {
+						errFile.compressWG.Wait()
+					}
+					close(l.done)
+					return
+				}
+			}
+		}
+	}
+}
+
+// 输出单条日志，输出过程中的panic会被恢复并打印到标准错误，输出协程继续处理后续日志
+func (l *Logger) safeWriteMsg(log *logMsg) {
+	// 同步模式下可能有多个协程同时输出，逐条串行写入
+	l.writeMu.Lock()
+	defer l.writeMu.Unlock()
+	if log.flushed == nil {
+		defer releaseMsg(log)
+	}
+	defer func() {
+		if err := recover(); err != nil {
+			fmt.Fprintln(os.Stderr, "MyLog: recovered from panic while writing log:", err)
+			// 避免等待刷新的调用方永远阻塞
+			if log.flushed != nil {
+				select {
+				case <-log.flushed:
+				default:
+					close(log.flushed)
+				}
+			}
+		}
+	}()
+	if log.control != nil {
+		l.mu.Lock()
+		log.control()
+		l.mu.Unlock()
+		close(log.flushed)
+		return
+	}
+	if log.batch != nil {
+		for _, m := range log.batch {
+			l.writeMsg(m)
+			releaseMsg(m)
+		}
+		return
+	}
+	l.writeMsg(log)
+}
+
+// 输出单条日志到各个目标
+func (l *Logger) writeMsg(log *logMsg) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	// 刷新请求之前的消息均已输出完毕，落盘后通知等待方
+	if log.flushed != nil {
+		l.flushRepeat()
+		l.syncFile()
+		if l.errFile != nil {
+			l.errFile.syncFile()
+		}
+		close(log.flushed)
+		return
+	}
+	if l.msgFilter != nil {
+		if log.msg = l.msgFilter(log.level, log.msg); log.msg == "" {
+			return
+		}
+	}
+	if l.maxLineLength > 0 {
+		log.msg = truncateMsg(log.msg, l.maxLineLength)
+	}
+	if l.sampling > 1 && !l.sample(log) {
+		return
+	}
+	if l.dedup && l.dedupe(log) {
+		return
+	}
+	l.writeLine(log)
+}
+
+// 格式化后写入终端、文件及其他输出目标，调用方需持有锁
+// 每个目标只调用一次Write写入包含换行的完整一行，避免多个目标或多个进程写入时行被拆散
+// line使用复用的缓冲，各目标不能在Write返回后继续持有
+func (l *Logger) writeLine(log *logMsg) {
+	content := l.formatLine(*log, false)
+	buf := linePool.Get().(*[]byte)
+	line := append(append((*buf)[:0], content...), '\n')
+	defer putLine(buf, line)
+	if l.ring != nil {
+		l.ring.add(line)
+	}
+	// 判断是否输出到终端
+	if l.OutputType&ONLY_TERMINAL == ONLY_TERMINAL && log.level >= l.terminalLevel {
+		// WARNING及以上等级按设置输出到错误输出
+		terminal, color := l.terminal, l.color
+		if l.errorToStderr && log.level >= WARNING {
+			terminal, color = l.errTerminal, l.errColor
+		}
+		var err error
+		if color {
+			_, err = terminal.Write([]byte(l.formatLine(*log, true) + "\n"))
+		} else {
+			_, err = terminal.Write(line)
+		}
+		if err != nil {
+			l.reportError(fmt.Errorf("write terminal failed: %w", err))
+		}
+	}
+	// 判断是否输出到文件
+	if l.OutputType&ONLY_FILE == ONLY_FILE && log.level >= l.fileLevel {
+		l.writeFile(line, log)
+	}
+	// 高等级日志额外写入错误文件
+	if l.errFile != nil && log.level >= WARNING {
+		l.errFile.writeFile(line, log)
+	}
+	// 输出到额外注册的目标
+	for _, w := range l.writers {
+		if _, err := w.Write(line); err != nil {
+			l.reportError(fmt.Errorf("write writer failed: %w", err))
+		}
+	}
+	for _, s := range l.sinks {
+		s.write(line)
+	}
+	if l.remote != nil {
+		l.remote.write(line)
+	}
+	if l.syslog != nil {
+		if err := l.syslog.writeLevel(log.level, content); err != nil {
+			l.reportError(fmt.Errorf("write syslog failed: %w", err))
+		}
+	}
+}
+
+// 复用日志消息结构体，减少每条日志的内存分配
+var logMsgPool = sync.Pool{New: func() interface{} { return new(logMsg) }}
+
+// 复用格式化一行日志的缓冲
+var linePool = sync.Pool{New: func() interface{} {
+	b := make([]byte, 0, 256)
+	return &b
+}}
+
+// 缓冲复用的上限，过长的行使用的缓冲不再放回，避免长期占用内存
+const maxPooledLine = 64 << 10
+
+// 回收格式化缓冲
+func putLine(buf *[]byte, line []byte) {
+	if cap(line) > maxPooledLine {
+		return
+	}
+	*buf = line
+	linePool.Put(buf)
+}
+
+// 回收已输出或被丢弃的日志消息，调用后不能再访问log
+func releaseMsg(log *logMsg) {
+	*log = logMsg{}
+	logMsgPool.Put(log)
+}
+
+// 回收未输出而被丢弃的消息，批量消息连同其中的各条一起回收，返回丢弃的日志条数
+func discardMsg(log *logMsg) uint64 {
+	n := uint64(1)
+	if log.batch != nil {
+		n = uint64(len(log.batch))
+		for _, m := range log.batch {
+			releaseMsg(m)
+		}
+	}
+	releaseMsg(log)
+	return n
+}
+
+// 处理一条日志消息，fields为附加在消息前的键值对字段
+func (l *Logger) handleLogMsg(logLevel LevelLog, msg interface{}, fields ...field) {
+	l.submit(nil, logLevel, nil, msg, fields, 0)
+}
+
+// 处理一条带context的日志消息，通道满需要等待时ctx结束则放弃该条日志
+func (l *Logger) handleLogMsgCtx(ctx context.Context, logLevel LevelLog, msg interface{}, fields ...field) {
+	l.submit(ctx, logLevel, nil, msg, fields, 0)
+}
+
+// 处理一条指定输出字段的日志消息，flags仅对本条消息生效
+func (l *Logger) handleLogMsgFlags(logLevel LevelLog, flags LogFlag, msg interface{}) {
+	l.submit(nil, logLevel, &flags, msg, nil, 0)
+}
+
+// 填充日志消息并放入通道，override非空时替代当前的输出字段设置
+// pc非0时使用其对应的调用位置，否则按调用栈深度获取
+// ctx非空时，通道满需要等待期间ctx结束则放弃该条日志并计入丢弃数
+func (l *Logger) submit(ctx context.Context, logLevel LevelLog, override *LogFlag, msg interface{}, fields []field, pc uintptr) {
+	// 关闭日志时直接返回，不加锁、不获取任何信息
+	// 使用mylog_nodebug构建时，slog、Writer等途径提交的TRACE、DEBUG日志同样丢弃
+	if l.isDisabled() || debugStripped && logLevel <= DEBUG {
+		return
+	}
+
+	// 读取配置快照，避免与设置函数竞争
+	l.mu.RLock()
+	level := l.Level
+	flags := l.Flags
+	timeFormat := l.timeFormat
+	timeUTC := l.timeUTC
+	timeMode := l.timeMode
+	start := l.start
+	clock := l.clock
+	overflow := l.overflow
+	callerSkip := l.callerSkip
+	caller := l.caller
+	pathSegments := l.pathSegments
+	stackLevel := l.stackLevel
+	hooks := l.hooks
+	name := l.name
+	fields = joinFields(getGlobalFields(), joinFields(l.fields, fields))
+	l.mu.RUnlock()
+	if override != nil {
+		flags = *override
+	}
+	// 模板由负责输出的对象持有，其用到的调用信息同样需要采集
+	capture := flags | l.backend().templateFlags()
+	if !caller {
+		// 关闭调用信息时不获取也不输出
+		flags &^= FLAG_FILENAME | FLAG_FUNCNAME | FLAG_LINENO
+		capture &^= FLAG_FILENAME | FLAG_FUNCNAME | FLAG_LINENO
+	}
+
+	// 低于当前日志等级或日志对象已关闭的消息直接丢弃
+	if logLevel < level || l.backend().isClosed() {
+		return
+	}
+	count := 1
+	items, isBatch := msg.(batchMsg)
+	if isBatch {
+		if count = len(items); count == 0 {
+			return
+		}
+	}
+	if logLevel <= FATAL {
+		atomic.AddUint64(&l.backend().levelCounts[logLevel], uint64(count))
+	}
+	// 所有ERROR、FATAL的输出路径都在此生成错误链字段，附加在单条日志的字段之后
+	if a, ok := msg.(argsMsg); ok && logLevel >= ERROR {
+		fields = joinFields(fields, l.errorFields(a.args))
+	}
+
+	// 处理收到的消息，填充结构体
+	now := time.Now()
+	if clock != nil {
+		now = clock()
+	}
+	var timeText string
+	if timeMode == TIME_ELAPSED {
+		// 转换为UTC会去掉单调时钟读数，先计算经过的时间
+		timeText = fmt.Sprintf("+%.4fs", now.Sub(start).Seconds())
+	}
+	if timeUTC {
+		now = now.UTC()
+	}
+	if timeMode != TIME_ELAPSED {
+		timeText = l.times.format(now, timeFormat)
+	}
+	log := logMsgPool.Get().(*logMsg)
+	*log = logMsg{
+		level:  logLevel,
+		flags:  flags,
+		name:   name,
+		msg:    formatMsg(msg),
+		time:   timeText,
+		at:     now,
+		fields: fields,
+	}
+	// 获取协程ID和调用信息开销较大，仅在需要输出时获取
+	if capture&FLAG_THREADID == FLAG_THREADID {
+		log.goID = getGoId()
+	}
+	if capture&(FLAG_FILENAME|FLAG_FUNCNAME|FLAG_LINENO) != 0 {
+		// 填充函数名和行号
+		if pc != 0 {
+			log.fileName, log.funcName, log.lineNo = pcCallerInfo(pc, pathSegments)
+		} else {
+			log.fileName, log.funcName, log.lineNo = getFuncCallerInfo(callerDepth+callerSkip, pathSegments)
+		}
+	}
+
+	if logLevel >= stackLevel {
+		log.stack = callerStack(callerDepth+callerSkip, pathSegments)
+	}
+
+	// 批量消息展开为多条，共享时间和调用信息，通过一次通道操作提交
+	var texts []string
+	if isBatch {
+		log.batch = make([]*logMsg, len(items))
+		texts = make([]string, len(items))
+		for i, item := range items {
+			m := logMsgPool.Get().(*logMsg)
+			*m = *log
+			m.batch = nil
+			m.msg = formatMsg(item)
+			if err, ok := item.(error); ok && logLevel >= ERROR {
+				m.fields = joinFields(log.fields, l.errorFields([]interface{}{err}))
 			}
+			log.batch[i], texts[i] = m, m.msg
 		}
-		// 判断是否输出到文件
-		if logger.OutputType&ONLY_FILE == ONLY_FILE {
-			fmt.Fprintln(logger.fileObj, content)
+	}
+
+	// 放入通道中，之后log会被输出协程回收，不能再访问
+	text := log.msg
+	if b := l.backend(); atomic.LoadInt32(&b.syncMode) != 0 {
+		// 同步模式直接在当前协程输出
+		b.safeWriteMsg(log)
+	} else {
+		var cancel <-chan struct{}
+		if ctx != nil {
+			cancel = ctx.Done()
 		}
+		b.enqueue(log, overflow, cancel)
+	}
+	if !isBatch {
+		runHooks(hooks, logLevel, text)
+		return
+	}
+	for _, text := range texts {
+		runHooks(hooks, logLevel, text)
 	}
 }
 
-func (l *Logger) handleLogMsg(logLevel LevelLog, msg interface{}) {
-	// 第一次收到消息时判断是否需要打开文件
-	onceOpenFile.Do(func() {
-		if l.OutputType&ONLY_FILE == ONLY_FILE {
-			fileObj, err := os.OpenFile(path.Join(logger.filePath, logger.fileName), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
-			if err != nil {
-				fmt.Println("open file failed, err:", err)
+// 按溢出策略将消息放入通道，阻塞等待期间cancel关闭则放弃该消息
+func (l *Logger) enqueue(log *logMsg, overflow OverflowPolicy, cancel <-chan struct{}) {
+	switch overflow {
+	case OVERFLOW_DROP:
+		select {
+		case l.msg <- log:
+		default:
+			atomic.AddUint64(&l.dropped, discardMsg(log))
+		}
+	case OVERFLOW_DROP_OLDEST:
+		for {
+			select {
+			case l.msg <- log:
 				return
+			default:
+			}
+			// 通道已满，取出最旧的一条后重试
+			select {
+			case old := <-l.msg:
+				if old.flushed != nil {
+					// 刷新请求不能丢弃，重新放回队尾
+					select {
+					case l.msg <- old:
+					case <-l.done:
+						discardMsg(log)
+						return
+					}
+					continue
+				}
+				atomic.AddUint64(&l.dropped, discardMsg(old))
+			default:
 			}
-			logger.fileObj = fileObj
 		}
+	default:
+		// 通道有空位时优先放入，避免ctx已结束时与空位随机选择
+		select {
+		case l.msg <- log:
+			return
+		default:
+		}
+		select {
+		case l.msg <- log:
+		case <-l.done:
+			discardMsg(log)
+		case <-cancel:
+			atomic.AddUint64(&l.dropped, discardMsg(log))
+		}
+	}
+}
+
+// 等待已提交的日志全部写入终端和文件，程序退出前应调用
+func (l *Logger) Flush() {
+	l.flush()
+}
 
+// 同Logger.Flush，作用于默认日志对象
+func Flush() {
+	logger.flush()
+}
+
+// 在输出协程中执行fn并等待其完成，用于修改只能在输出协程中读写的状态，执行时持有写锁
+// 输出协程已退出时在当前协程中执行
+func (l *Logger) inWriter(fn func()) {
+	l = l.backend()
+	done := make(chan struct{})
+	select {
+	case l.msg <- &logMsg{flushed: done, control: fn}:
+	case <-l.done:
+		l.writeMu.Lock()
+		defer l.writeMu.Unlock()
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		fn()
+		return
+	}
+	select {
+	case <-done:
+	case <-l.done:
+	}
+}
+
+// 等待通道中已有的日志全部输出完毕
+func (l *Logger) flush() {
+	l = l.backend()
+	flushed := make(chan struct{})
+	select {
+	case l.msg <- &logMsg{flushed: flushed}:
+	case <-l.done:
+		return
+	}
+	select {
+	case <-flushed:
+	case <-l.done:
+	}
+}
+
+// Logger实现io.Closer，可用于defer l.Close()等惯用写法
+var _ io.Closer = (*Logger)(nil)
+
+// 关闭日志对象：输出剩余日志、关闭文件并停止输出协程，关闭后的日志将被丢弃
+// 可重复调用，之后的调用直接返回第一次关闭的结果
+func (l *Logger) Close() error {
+	l = l.backend()
+	l.closeOnce.Do(func() {
+		close(l.quit)
 	})
-	// 处理收到的消息，填充结构体
-	log := &logMsg{
-		level: logLevel,
-		msg:   fmt.Sprint(msg),
-		time:  time.Now().Format("2006-01-02 15:04:05"),
-		goID : getGoId(),
+	<-l.done
+	return l.closeErr
+}
+
+// 同Logger.Close，作用于默认日志对象
+func Close() error {
+	return logger.Close()
+}
+
+// 收到任一指定信号时输出剩余日志并关闭日志对象，如CloseOnSignal(os.Interrupt, syscall.SIGTERM)
+// 关闭后停止捕获并重新发送该信号，保持信号原有的行为（默认结束进程，程序另有捕获时交由其处理）
+// 不支持重新发送信号的平台上以状态码1退出；日志对象先被关闭时停止捕获
+func (l *Logger) CloseOnSignal(sigs ...os.Signal) {
+	l = l.backend()
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, sigs...)
+	go func() {
+		select {
+		case sig := <-c:
+			l.Close()
+			signal.Stop(c)
+			if err := raiseSignal(sig); err != nil {
+				exit(1)
+			}
+		case <-l.done:
+			signal.Stop(c)
+		}
+	}()
+}
+
+// 同Logger.CloseOnSignal，作用于默认日志对象
+func CloseOnSignal(sigs ...os.Signal) {
+	logger.CloseOnSignal(sigs...)
+}
+
+// 获取实际负责输出的日志对象，Named创建的子对象共享父对象的输出
+func (l *Logger) backend() *Logger {
+	if l.root != nil {
+		return l.root
+	}
+	return l
+}
+
+// 创建带组件名称的子日志对象，名称显示在等级之后，如[auth]，多级名称以.连接
+// 子对象继承父对象当前的等级、输出字段等设置，之后可独立修改；输出（通道、文件、输出目标、格式等）与父对象共享，相关设置需在父对象上修改
+func (l *Logger) Named(name string) *Logger {
+	child := l.derive()
+	if child.name != "" {
+		name = child.name + "." + name
+	}
+	child.name = name
+	return child
+}
+
+// 创建附带固定字段的子日志对象，子对象输出的每条日志都带上这些字段，同名字段以单条日志的字段为准
+// 与Named相同，子对象共享父对象的输出，修改子对象的等级、输出字段等设置不影响父对象
+func (l *Logger) With(fields Fields) *Logger {
+	child := l.derive()
+	child.fields = mergeFields(child.fields, fields)
+	return child
+}
+
+// 同Logger.With，作用于默认日志对象
+func With(fields Fields) *Logger {
+	return logger.With(fields)
+}
+
+// 复制提交消息时使用的设置，创建共享输出的子对象
+func (l *Logger) derive() *Logger {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return &Logger{
+		Level:        l.Level,
+		LevelStr:     l.LevelStr,
+		OutputType:   l.OutputType,
+		Flags:        l.Flags,
+		timeFormat:   l.timeFormat,
+		timeUTC:      l.timeUTC,
+		timeMode:     l.timeMode,
+		start:        l.start,
+		clock:        l.clock,
+		overflow:     l.overflow,
+		callerSkip:   l.callerSkip,
+		caller:       l.caller,
+		pathSegments: l.pathSegments,
+		stackLevel:   l.stackLevel,
+		hooks:        l.hooks,
+		disabled:     atomic.LoadInt32(&l.disabled),
+		errorChain:   atomic.LoadInt32(&l.errorChain),
+		name:         l.name,
+		fields:       l.fields,
+		root:         l.backend(),
+	}
+}
+
+// 同Logger.Named，作用于默认日志对象
+func Named(name string) *Logger {
+	return logger.Named(name)
+}
+
+// 判断日志对象是否已关闭
+func (l *Logger) isClosed() bool {
+	select {
+	case <-l.quit:
+		return true
+	default:
+		return false
+	}
+}
+
+// 判断是否已关闭日志，子对象自身或共享输出的父对象关闭时均不再输出
+func (l *Logger) isDisabled() bool {
+	return atomic.LoadInt32(&l.disabled) != 0 || atomic.LoadInt32(&l.backend().disabled) != 0
+}
+
+// 设置是否启用日志，关闭后所有日志调用立即返回，可在运行时随时切换
+// 在父对象上关闭时Named、With创建的子对象也不再输出，在子对象上关闭只影响该子对象
+func (l *Logger) SetEnabled(enable bool) {
+	if enable {
+		atomic.StoreInt32(&l.disabled, 0)
+	} else {
+		atomic.StoreInt32(&l.disabled, 1)
+	}
+}
+
+// 同Logger.SetEnabled，作用于默认日志对象
+func SetEnabled(enable bool) {
+	logger.SetEnabled(enable)
+}
+
+// 设置同步模式：开启后日志在调用协程中直接写入，函数返回时已输出完毕，无需Flush，但调用方需承担写入耗时
+func (l *Logger) SetSync(enable bool) {
+	l = l.backend()
+	if enable {
+		// 先输出通道中已有的日志，保证顺序
+		l.flush()
+		atomic.StoreInt32(&l.syncMode, 1)
+	} else {
+		atomic.StoreInt32(&l.syncMode, 0)
+	}
+}
+
+// 同Logger.SetSync，作用于默认日志对象
+func SetSync(enable bool) {
+	logger.SetSync(enable)
+}
+
+// 设置日志等级，超出TRACE~FATAL范围的值将被忽略
+func (l *Logger) SetLevel(level LevelLog) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if level > FATAL {
+		return
+	}
+	l.Level = level
+}
+
+// 同Logger.SetLevel，作用于默认日志对象
+func SetLevel(level LevelLog) {
+	logger.SetLevel(level)
+}
+
+// 获取当前日志等级
+func (l *Logger) GetLevel() LevelLog {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.Level
+}
+
+// 同Logger.GetLevel，作用于默认日志对象
+func GetLevel() LevelLog {
+	return logger.GetLevel()
+}
+
+// 判断该等级的日志是否会被输出，可在构造开销较大的日志内容前判断，使用mylog_nodebug构建时TRACE、DEBUG始终返回false
+func (l *Logger) IsEnabled(level LevelLog) bool {
+	if l.isDisabled() || debugStripped && level <= DEBUG {
+		return false
+	}
+	return level >= l.GetLevel()
+}
+
+// 同Logger.IsEnabled，作用于默认日志对象
+func IsEnabled(level LevelLog) bool {
+	return logger.IsEnabled(level)
+}
+
+// 设置日志等级的显示标识，如将INFO显示为"I"
+func (l *Logger) SetLevelString(level LevelLog, label string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	// 复制后再修改，避免影响输出协程正在读取的map
+	levelStr := make(map[LevelLog]string, len(l.LevelStr))
+	for k, v := range l.LevelStr {
+		levelStr[k] = v
+	}
+	levelStr[level] = label
+	l.LevelStr = levelStr
+}
+
+// 同Logger.SetLevelString，作用于默认日志对象
+func SetLevelString(level LevelLog, label string) {
+	logger.SetLevelString(level, label)
+}
+
+// 获取日志等级当前的显示标识
+func (l *Logger) GetLevelString(level LevelLog) string {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.levelString(level)
+}
+
+// 同Logger.GetLevelString，作用于默认日志对象
+func GetLevelString(level LevelLog) string {
+	return logger.GetLevelString(level)
+}
+
+// 查找日志等级的显示标识，调用方需持有锁
+// LevelStr只会整体替换不会原地修改，读取到的map在持有锁期间不会变化
+func (l *Logger) levelString(level LevelLog) string {
+	if label, ok := l.LevelStr[level]; ok {
+		return label
+	}
+	return fmt.Sprintf("LEVEL%d", level)
+}
+
+// 设置文本格式下是否保留等级标识末尾用于对齐的空格，默认保留，JSON、logfmt格式始终去掉
+func (l *Logger) SetLevelPadding(padding bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.levelPadding = padding
+}
+
+// 同Logger.SetLevelPadding，作用于默认日志对象
+func SetLevelPadding(padding bool) {
+	logger.SetLevelPadding(padding)
+}
+
+// 获取文本格式使用的等级标识，调用方需持有锁
+func (l *Logger) textLevel(level LevelLog) string {
+	if l.levelPadding {
+		return l.levelString(level)
+	}
+	return strings.TrimRight(l.levelString(level), " ")
+}
+
+// 恢复默认的日志等级标识
+func (l *Logger) ResetLevelString() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.LevelStr = defaultLevelStr()
+}
+
+// 同Logger.ResetLevelString，作用于默认日志对象
+func ResetLevelString() {
+	logger.ResetLevelString()
+}
+
+// 设置获取调用信息时额外跳过的栈帧数，对日志函数再做一层封装时设置为1，以输出封装函数调用方的信息
+func (l *Logger) SetCallerSkip(n int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.callerSkip = n
+}
+
+// 同Logger.SetCallerSkip，作用于默认日志对象
+func SetCallerSkip(n int) {
+	logger.SetCallerSkip(n)
+}
+
+// 设置该等级及以上的日志附带完整调用栈，调用栈输出在消息之后，传入大于FATAL的值关闭
+func (l *Logger) SetStackTrace(minLevel LevelLog) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.stackLevel = minLevel
+}
+
+// 同Logger.SetStackTrace，作用于默认日志对象
+func SetStackTrace(minLevel LevelLog) {
+	logger.SetStackTrace(minLevel)
+}
+
+// 设置是否获取调用信息，关闭后不再调用runtime.Caller，无论是否设置了FLAG_FILENAME、FLAG_FUNCNAME、FLAG_LINENO均不输出这些信息
+// 获取调用信息是输出日志的主要开销，对性能敏感的场景可关闭，默认开启
+func (l *Logger) SetCaller(enable bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.caller = enable
+}
+
+// 同Logger.SetCaller，作用于默认日志对象
+func SetCaller(enable bool) {
+	logger.SetCaller(enable)
+}
+
+// 设置是否输出完整的文件路径，false时只输出文件名
+func (l *Logger) SetFullPath(full bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if full {
+		l.pathSegments = 0
+	} else {
+		l.pathSegments = 1
+	}
+}
+
+// 同Logger.SetFullPath，作用于默认日志对象
+func SetFullPath(full bool) {
+	logger.SetFullPath(full)
+}
+
+// 设置文件名保留末尾的路径段数，如2输出sub/file.go，小于等于0时输出完整路径
+func (l *Logger) SetPathSegments(n int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if n < 0 {
+		n = 0
 	}
-	// 填充函数名和行号
-	fileName, funName, lineNo := getFuncCallerInfo()
-	log.fileName = fileName
-	log.funcName = funName
-	log.lineNo = lineNo
+	l.pathSegments = n
+}
+
+// 同Logger.SetPathSegments，作用于默认日志对象
+func SetPathSegments(n int) {
+	logger.SetPathSegments(n)
+}
+
+// 设置通道满时的处理策略
+func (l *Logger) SetOverflowPolicy(policy OverflowPolicy) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.overflow = policy
+}
+
+// 同Logger.SetOverflowPolicy，作用于默认日志对象
+func SetOverflowPolicy(policy OverflowPolicy) {
+	logger.SetOverflowPolicy(policy)
+}
+
+// 获取被丢弃的消息数，包括通道已满、ctx结束时放弃的消息，以及远程发送缓冲和AddAsyncWriter目标缓冲已满时丢弃的行
+func (l *Logger) DroppedCount() uint64 {
+	return atomic.LoadUint64(&l.backend().dropped)
+}
 
-	// 放入通道中
-	l.msg <- log
+// 同Logger.DroppedCount，作用于默认日志对象
+func DroppedCount() uint64 {
+	return logger.DroppedCount()
+}
+
+// 获取该等级已输出的消息数，子对象与父对象共享计数，被等级过滤的消息不计入
+func (l *Logger) LevelCount(level LevelLog) uint64 {
+	if level > FATAL {
+		return 0
+	}
+	return atomic.LoadUint64(&l.backend().levelCounts[level])
+}
+
+// 同Logger.LevelCount，作用于默认日志对象
+func LevelCount(level LevelLog) uint64 {
+	return logger.LevelCount(level)
+}
+
+// 设置失败判定等级，输出过该等级及以上的日志后ShouldFail返回true，传入大于FATAL的值关闭
+// 适用于CI中将ERROR日志视为失败，判定基于LevelCount计数，子对象与父对象共享
+func (l *Logger) SetFailOnLevel(minLevel LevelLog) {
+	root := l.backend()
+	root.mu.Lock()
+	defer root.mu.Unlock()
+	root.failLevel = minLevel
+}
+
+// 同Logger.SetFailOnLevel，作用于默认日志对象
+func SetFailOnLevel(minLevel LevelLog) {
+	logger.SetFailOnLevel(minLevel)
+}
+
+// 是否输出过SetFailOnLevel设置等级及以上的日志，未设置时返回false
+func (l *Logger) ShouldFail() bool {
+	root := l.backend()
+	root.mu.RLock()
+	minLevel := root.failLevel
+	root.mu.RUnlock()
+	for level := minLevel; level <= FATAL; level++ {
+		if root.LevelCount(level) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// 同Logger.ShouldFail，作用于默认日志对象
+func ShouldFail() bool {
+	return logger.ShouldFail()
+}
+
+// ShouldFail返回true时等待已提交的日志输出完毕并以退出码1退出进程，否则直接返回
+// 通常在main函数末尾调用
+func (l *Logger) ExitOnFail() {
+	if !l.ShouldFail() {
+		return
+	}
+	l.flush()
+	exit(1)
+}
+
+// 同Logger.ExitOnFail，作用于默认日志对象
+func ExitOnFail() {
+	logger.ExitOnFail()
+}
+
+// 设置终端输出目标，可替换为任意io.Writer（如bytes.Buffer、网络连接）
+// 启用SetErrorToStderr时WARNING及以上等级写入SetErrorOutput设置的目标
+func (l *Logger) SetOutput(w io.Writer) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.terminal = w
+	if !l.colorForced {
+		l.color = isTerminal(w)
+	}
+}
+
+// 同Logger.SetOutput，作用于默认日志对象
+func SetOutput(w io.Writer) {
+	logger.SetOutput(w)
+}
+
+// 设置终端的错误输出目标，默认为标准错误
+func (l *Logger) SetErrorOutput(w io.Writer) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.errTerminal = w
+	if !l.colorForced {
+		l.errColor = isTerminal(w)
+	}
+}
+
+// 同Logger.SetErrorOutput，作用于默认日志对象
+func SetErrorOutput(w io.Writer) {
+	logger.SetErrorOutput(w)
+}
+
+// 设置终端输出时WARNING及以上等级是否写入错误输出，默认为true，false时全部写入SetOutput设置的目标
+func (l *Logger) SetErrorToStderr(enable bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.errorToStderr = enable
+}
+
+// 同Logger.SetErrorToStderr，作用于默认日志对象
+func SetErrorToStderr(enable bool) {
+	logger.SetErrorToStderr(enable)
+}
+
+// 添加额外的输出目标，每条日志都会写入所有已添加的目标，在子对象上调用时添加到父对象
+func (l *Logger) AddWriter(w io.Writer) {
+	l = l.backend()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	// 复制一份新切片，避免与输出协程读取的切片冲突
+	writers := make([]io.Writer, 0, len(l.writers)+1)
+	writers = append(writers, l.writers...)
+	l.writers = append(writers, w)
+}
+
+// 同Logger.AddWriter，作用于默认日志对象
+func AddWriter(w io.Writer) {
+	logger.AddWriter(w)
+}
+
+// 移除通过AddWriter或AddAsyncWriter添加的输出目标，独立写入的目标会等待其缓冲写完
+func (l *Logger) RemoveWriter(w io.Writer) {
+	l = l.backend()
+	l.mu.Lock()
+	writers := make([]io.Writer, 0, len(l.writers))
+	for _, item := range l.writers {
+		if item != w {
+			writers = append(writers, item)
+		}
+	}
+	l.writers = writers
+	sinks := make([]*asyncSink, 0, len(l.sinks))
+	var removed []*asyncSink
+	for _, s := range l.sinks {
+		if s.w == w {
+			removed = append(removed, s)
+		} else {
+			sinks = append(sinks, s)
+		}
+	}
+	l.sinks = sinks
+	l.mu.Unlock()
+	// 关闭时可能等待数秒，释放锁后再关闭
+	for _, s := range removed {
+		s.Close()
+	}
+}
+
+// 同Logger.RemoveWriter，作用于默认日志对象
+func RemoveWriter(w io.Writer) {
+	logger.RemoveWriter(w)
 }
 
 // 设置输出类型
+func (l *Logger) SetOutputType(outputType OutputType) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.OutputType = outputType
+}
+
+// 同Logger.SetOutputType，作用于默认日志对象
 func SetOutputType(outputType OutputType) {
-	logger.OutputType = outputType
+	logger.SetOutputType(outputType)
 }
 
-// 设置输出类型
+// 设置终端输出的最低等级，如开发时终端输出DEBUG而文件只记录INFO及以上，整体的Level仍是下限
+func (l *Logger) SetTerminalLevel(level LevelLog) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.terminalLevel = level
+}
+
+// 同Logger.SetTerminalLevel，作用于默认日志对象
+func SetTerminalLevel(level LevelLog) {
+	logger.SetTerminalLevel(level)
+}
+
+// 设置文件输出的最低等级，整体的Level仍是下限
+func (l *Logger) SetFileLevel(level LevelLog) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.fileLevel = level
+}
+
+// 同Logger.SetFileLevel，作用于默认日志对象
+func SetFileLevel(level LevelLog) {
+	logger.SetFileLevel(level)
+}
+
+// 按名称解析输出类型，支持terminal、file、both，不区分大小写
+func ParseOutputType(name string) (OutputType, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "terminal":
+		return ONLY_TERMINAL, nil
+	case "file":
+		return ONLY_FILE, nil
+	case "both":
+		return BOTH_TERMINAL_AND_FILE, nil
+	}
+	return 0, fmt.Errorf("unknown output type: %q", name)
+}
+
+// 按名称设置输出类型，名称无效时返回错误且不修改设置
+func (l *Logger) SetOutputTypeByName(name string) error {
+	outputType, err := ParseOutputType(name)
+	if err != nil {
+		return err
+	}
+	l.SetOutputType(outputType)
+	return nil
+}
+
+// 同Logger.SetOutputTypeByName，作用于默认日志对象
+func SetOutputTypeByName(name string) error {
+	return logger.SetOutputTypeByName(name)
+}
+
+// 设置输出字段
+func (l *Logger) SetFlags(flags LogFlag) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.Flags = flags
+}
+
+// 同Logger.SetFlags，作用于默认日志对象
 func SetFlags(flags LogFlag) {
-	logger.Flags = flags
+	logger.SetFlags(flags)
+}
+
+// 设置时间格式，格式同time.Format，如time.RFC3339
+func (l *Logger) SetTimeFormat(layout string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.timeFormat = layout
+}
+
+// 同Logger.SetTimeFormat，作用于默认日志对象
+func SetTimeFormat(layout string) {
+	logger.SetTimeFormat(layout)
+}
+
+// 设置是否使用UTC时间
+func (l *Logger) SetTimeUTC(utc bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.timeUTC = utc
+}
+
+// 同Logger.SetTimeUTC，作用于默认日志对象
+func SetTimeUTC(utc bool) {
+	logger.SetTimeUTC(utc)
+}
+
+// 设置时间字段的记录方式，TIME_ELAPSED时输出自日志对象创建以来经过的时间，基于单调时钟不受系统时间调整影响
+func (l *Logger) SetTimeMode(mode TimeMode) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.timeMode = mode
+}
+
+// 同Logger.SetTimeMode，作用于默认日志对象
+func SetTimeMode(mode TimeMode) {
+	logger.SetTimeMode(mode)
+}
+
+// 设置获取当前时间的函数，用于测试中固定时间，日志时间和按时间滚动均以此为准，传nil恢复使用time.Now
+// 设置时以该函数的当前时间作为TIME_ELAPSED的起点
+func (l *Logger) SetClock(clock func() time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.clock = clock
+	l.start = l.now()
+}
+
+// 同Logger.SetClock，作用于默认日志对象
+func SetClock(clock func() time.Time) {
+	logger.SetClock(clock)
 }
 
-// 设置log文件名称
-func SetFileName(name string) {
-	logger.fileName = name
+// 获取当前时间，调用方需持有锁
+func (l *Logger) now() time.Time {
+	if l.clock != nil {
+		return l.clock()
+	}
+	return time.Now()
+}
+
+// 设置log文件名称，已开始输出时会关闭原文件，之后的日志写入新文件
+// 输出类型包含文件时立即打开新文件，打开失败时返回错误
+func (l *Logger) SetFileName(name string) error {
+	// 先输出已提交的日志，保证其写入原文件
+	l.flush()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.fileName = name
+	l.resetFile()
+	return l.openNow()
+}
+
+// 同Logger.SetFileName，作用于默认日志对象
+func SetFileName(name string) error {
+	return logger.SetFileName(name)
+}
+
+// 设置log文件所在目录，目录不存在时自动创建，已开始输出时会关闭原文件，之后的日志写入新目录
+// 输出类型包含文件时立即打开新文件，目录创建或文件打开失败时返回错误
+func (l *Logger) SetFilePath(dir string) error {
+	l.flush()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.filePath = dir
+	l.resetFile()
+	return l.openNow()
+}
+
+// 同Logger.SetFilePath，作用于默认日志对象
+func SetFilePath(dir string) error {
+	return logger.SetFilePath(dir)
 }
 
 // 信息输出
-func Info(msg interface{}) {
-	logger.handleLogMsg(INFO, msg)
+func (l *Logger) Info(args ...interface{}) {
+	l.handleLogMsg(INFO, sprintln(args...))
+}
+
+// 警告信息输出
+func (l *Logger) Warning(args ...interface{}) {
+	l.handleLogMsg(WARNING, sprintln(args...))
+}
+
+// 严重错误信息输出，输出完毕后退出进程
+func (l *Logger) Fatal(args ...interface{}) {
+	l.handleLogMsg(FATAL, argsMsg{text: sprintln(args...), args: args})
+	l.flush()
+	exit(1)
+}
+
+// 错误信息输出
+func (l *Logger) Error(args ...interface{}) {
+	l.handleLogMsg(ERROR, argsMsg{text: sprintln(args...), args: args})
+}
+
+// 格式化信息输出
+func (l *Logger) Infof(format string, args ...interface{}) {
+	l.handleLogMsg(INFO, fmt.Sprintf(format, args...))
+}
+
+// 格式化警告信息输出
+func (l *Logger) Warningf(format string, args ...interface{}) {
+	l.handleLogMsg(WARNING, fmt.Sprintf(format, args...))
+}
+
+// 格式化严重错误信息输出，输出完毕后退出进程
+func (l *Logger) Fatalf(format string, args ...interface{}) {
+	l.handleLogMsg(FATAL, argsMsg{text: fmt.Sprintf(format, args...), args: args})
+	l.flush()
+	exit(1)
 }
 
-// 调试信息输出
-func Debug(msg interface{}) {
-	logger.handleLogMsg(DEBUG, msg)
+// 格式化错误信息输出
+func (l *Logger) Errorf(format string, args ...interface{}) {
+	l.handleLogMsg(ERROR, argsMsg{text: fmt.Sprintf(format, args...), args: args})
+}
+
+// 信息输出
+func Info(args ...interface{}) {
+	logger.handleLogMsg(INFO, sprintln(args...))
 }
 
 // 警告信息输出
-func Warning(msg interface{}) {
-	logger.handleLogMsg(WARNING, msg)
+func Warning(args ...interface{}) {
+	logger.handleLogMsg(WARNING, sprintln(args...))
 }
 
-// 严重错误信息输出
-func Fatal(msg interface{}) {
-	logger.handleLogMsg(FATAL, msg)
+// 严重错误信息输出，输出完毕后退出进程
+func Fatal(args ...interface{}) {
+	logger.handleLogMsg(FATAL, argsMsg{text: sprintln(args...), args: args})
+	logger.flush()
+	exit(1)
 }
 
 // 错误信息输出
-func Error(msg interface{}) {
-	logger.handleLogMsg(ERROR, msg)
+func Error(args ...interface{}) {
+	logger.handleLogMsg(ERROR, argsMsg{text: sprintln(args...), args: args})
 }
 
-// 获取协程ID
-func getGoId() int {
-	defer func() {
-		if err := recover(); err != nil {
-		}
-	}()
+// 格式化信息输出
+func Infof(format string, args ...interface{}) {
+	logger.handleLogMsg(INFO, fmt.Sprintf(format, args...))
+}
+
+// 格式化警告信息输出
+func Warningf(format string, args ...interface{}) {
+	logger.handleLogMsg(WARNING, fmt.Sprintf(format, args...))
+}
+
+// 格式化严重错误信息输出，输出完毕后退出进程
+func Fatalf(format string, args ...interface{}) {
+	logger.handleLogMsg(FATAL, argsMsg{text: fmt.Sprintf(format, args...), args: args})
+	logger.flush()
+	exit(1)
+}
+
+// 格式化错误信息输出
+func Errorf(format string, args ...interface{}) {
+	logger.handleLogMsg(ERROR, argsMsg{text: fmt.Sprintf(format, args...), args: args})
+}
 
+// 按指定的输出字段信息输出，flags仅对本条消息生效
+func (l *Logger) InfoWithFlags(flags LogFlag, args ...interface{}) {
+	l.handleLogMsgFlags(INFO, flags, sprintln(args...))
+}
+
+// 按指定的输出字段警告信息输出，flags仅对本条消息生效
+func (l *Logger) WarningWithFlags(flags LogFlag, args ...interface{}) {
+	l.handleLogMsgFlags(WARNING, flags, sprintln(args...))
+}
+
+// 按指定的输出字段错误信息输出，flags仅对本条消息生效
+func (l *Logger) ErrorWithFlags(flags LogFlag, args ...interface{}) {
+	l.handleLogMsgFlags(ERROR, flags, argsMsg{text: sprintln(args...), args: args})
+}
+
+// 按指定的输出字段严重错误信息输出，flags仅对本条消息生效，输出完毕后退出进程
+func (l *Logger) FatalWithFlags(flags LogFlag, args ...interface{}) {
+	l.handleLogMsgFlags(FATAL, flags, argsMsg{text: sprintln(args...), args: args})
+	l.flush()
+	exit(1)
+}
+
+// 按指定的输出字段信息输出，flags仅对本条消息生效
+func InfoWithFlags(flags LogFlag, args ...interface{}) {
+	logger.handleLogMsgFlags(INFO, flags, sprintln(args...))
+}
+
+// 按指定的输出字段警告信息输出，flags仅对本条消息生效
+func WarningWithFlags(flags LogFlag, args ...interface{}) {
+	logger.handleLogMsgFlags(WARNING, flags, sprintln(args...))
+}
+
+// 按指定的输出字段错误信息输出，flags仅对本条消息生效
+func ErrorWithFlags(flags LogFlag, args ...interface{}) {
+	logger.handleLogMsgFlags(ERROR, flags, argsMsg{text: sprintln(args...), args: args})
+}
+
+// 按指定的输出字段严重错误信息输出，flags仅对本条消息生效，输出完毕后退出进程
+func FatalWithFlags(flags LogFlag, args ...interface{}) {
+	logger.handleLogMsgFlags(FATAL, flags, argsMsg{text: sprintln(args...), args: args})
+	logger.flush()
+	exit(1)
+}
+
+// 延迟生成的日志消息，仅在日志需要输出时才调用
+type lazyMsg func() string
+
+// 批量提交的多条消息，每个元素为一条
+type batchMsg []interface{}
+
+// 已格式化的消息及其原始参数，ERROR及以上等级提交时从参数中取出error生成错误链字段
+type argsMsg struct {
+	text string
+	args []interface{}
+}
+
+// 生成消息文本，延迟消息在此时才调用，批量消息的文本由各条单独生成
+func formatMsg(msg interface{}) string {
+	switch msg := msg.(type) {
+	case lazyMsg:
+		return msg()
+	case batchMsg:
+		return ""
+	case argsMsg:
+		return msg.text
+	}
+	return fmt.Sprint(msg)
+}
+
+// 延迟生成消息的信息输出，仅在该等级需要输出时才调用fn
+func (l *Logger) InfoFunc(fn func() string) {
+	l.handleLogMsg(INFO, lazyMsg(fn))
+}
+
+// 延迟生成消息的警告信息输出，仅在该等级需要输出时才调用fn
+func (l *Logger) WarningFunc(fn func() string) {
+	l.handleLogMsg(WARNING, lazyMsg(fn))
+}
+
+// 延迟生成消息的错误信息输出，仅在该等级需要输出时才调用fn
+func (l *Logger) ErrorFunc(fn func() string) {
+	l.handleLogMsg(ERROR, lazyMsg(fn))
+}
+
+// 延迟生成消息的严重错误信息输出，仅在该等级需要输出时才调用fn，输出完毕后退出进程
+func (l *Logger) FatalFunc(fn func() string) {
+	l.handleLogMsg(FATAL, lazyMsg(fn))
+	l.flush()
+	exit(1)
+}
+
+// 延迟生成消息的信息输出，仅在该等级需要输出时才调用fn
+func InfoFunc(fn func() string) {
+	logger.handleLogMsg(INFO, lazyMsg(fn))
+}
+
+// 延迟生成消息的警告信息输出，仅在该等级需要输出时才调用fn
+func WarningFunc(fn func() string) {
+	logger.handleLogMsg(WARNING, lazyMsg(fn))
+}
+
+// 延迟生成消息的错误信息输出，仅在该等级需要输出时才调用fn
+func ErrorFunc(fn func() string) {
+	logger.handleLogMsg(ERROR, lazyMsg(fn))
+}
+
+// 延迟生成消息的严重错误信息输出，仅在该等级需要输出时才调用fn，输出完毕后退出进程
+func FatalFunc(fn func() string) {
+	logger.handleLogMsg(FATAL, lazyMsg(fn))
+	logger.flush()
+	exit(1)
+}
+
+// 将多个参数以空格拼接为一条消息（与fmt.Sprintln一致，但去掉末尾换行）
+func sprintln(args ...interface{}) string {
+	msg := fmt.Sprintln(args...)
+	return msg[:len(msg)-1]
+}
+
+// 获取协程ID，无法解析时返回0（协程ID从1开始，0表示未知）
+func getGoId() int {
 	var buf [64]byte
 	n := runtime.Stack(buf[:], false)
-	idField := strings.Fields(strings.TrimPrefix(string(buf[:n]), "goroutine "))[0]
-	id, err := strconv.Atoi(idField)
+	fields := strings.Fields(strings.TrimPrefix(string(buf[:n]), "goroutine "))
+	if len(fields) == 0 {
+		return 0
+	}
+	id, err := strconv.Atoi(fields[0])
 	if err != nil {
-		panic(fmt.Sprintf("cannot get goroutine id: %v", err))
+		return 0
 	}
 	return id
 }
 
-// 获取打印日志语句所在函数的信息（文件名 函数名 行号）
-func getFuncCallerInfo() (fileName string, funcName string, lineNo int) {
-	pc, fileName, lineNo, ok := runtime.Caller(3)
+// 格式化组件名称标识，未设置名称时返回空
+func (l *Logger) formatName(name string) string {
+	if name == "" {
+		return ""
+	}
+	return l.bracket(name) + l.separator
+}
+
+// 格式化协程ID标识，ID未知时返回空
+func (l *Logger) formatGoId(goID int) string {
+	if goID <= 0 {
+		return ""
+	}
+	return l.bracket(fmt.Sprintf("goroutine %d", goID)) + l.separator
+}
+
+// 用设置的括号包裹前缀的一部分
+func (l *Logger) bracket(s string) string {
+	return l.bracketOpen + s + l.bracketClose
+}
+
+// 获取打印日志语句所在函数的信息（文件名 函数名 行号），skip为相对本函数跳过的栈帧数，segments为文件名保留的路径段数
+func getFuncCallerInfo(skip int, segments int) (fileName string, funcName string, lineNo int) {
+	pc, fileName, lineNo, ok := runtime.Caller(skip)
 	if !ok {
-		fmt.Println("get FuncCaller Info failed")
+		// 栈深度不足时不输出调用信息，库不向标准输出打印任何内容
+		return "", "", 0
 	}
 
-	// 获取到的是完整文件名，需要去除文件路径
-	_, fileName = path.Split(fileName)
+	// 获取到的是完整文件名，按设置保留末尾的若干段路径
+	fileName = trimPath(fileName, segments)
 
 	// 获取函数名
-	funcName = runtime.FuncForPC(pc).Name()
-	temp := strings.Split(funcName, ".")
-	funcName = strings.Join(temp[1:], "")
+	if fn := runtime.FuncForPC(pc); fn != nil {
+		funcName = trimFuncName(fn.Name())
+	}
 
 	return fileName, funcName, lineNo
 }
 
-// 通过falgs形成前缀
-func (l *Logger) formatPrefix(log logMsg) string {
-	// 判断无标志则返回为空
-	if logger.Flags == FLAG_NONE {
-		return ""
+// 获取从skip开始的调用栈，每帧两行：函数名、文件名:行号
+func callerStack(skip int, segments int) string {
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(skip+1, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	var b strings.Builder
+	for {
+		frame, more := frames.Next()
+		fmt.Fprintf(&b, "\t%s()\n\t\t%s:%d\n", trimFuncName(frame.Function), trimPath(frame.File, segments), frame.Line)
+		if !more {
+			break
+		}
 	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
 
-	// 标识全有则按照固定格式输出所有信息
-	if logger.Flags == FLAG_ALL {
-		return fmt.Sprintf("[%s] [%s] [%s %s() line%d] [goId:%d] ", log.time, logger.LevelStr[log.level], log.fileName, log.funcName, log.lineNo, log.goID)
-	}
+// 根据程序计数器获取文件名、函数名和行号
+func pcCallerInfo(pc uintptr, segments int) (fileName string, funcName string, lineNo int) {
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	return trimPath(frame.File, segments), trimFuncName(frame.Function), frame.Line
+}
 
-	// 否则按照标识进行组合
-	var prefix string
-	if logger.Flags&FLAG_TIME == FLAG_TIME {
-		prefix += fmt.Sprintf("[%s]", log.time)
+// 去掉函数全名中的包路径，保留类型和方法名
+// 如github.com/a/b.(*T).Method -> (*T).Method，pkg.Func.func1 -> Func.func1
+func trimFuncName(name string) string {
+	// 包路径中可能含有.，先去掉最后一个/之前的部分
+	if idx := strings.LastIndex(name, "/"); idx >= 0 {
+		name = name[idx+1:]
 	}
+	// 剩余部分第一个.之前为包名
+	if idx := strings.Index(name, "."); idx >= 0 {
+		name = name[idx+1:]
+	}
+	return name
+}
 
-	if logger.Flags&FLAG_LEVEL == FLAG_LEVEL {
-		if len(prefix) > 0 {
-			prefix += " " + fmt.Sprintf("[%s]", logger.LevelStr[log.level])
-		} else {
-			prefix += fmt.Sprintf("[%s]", logger.LevelStr[log.level])
+// 保留路径末尾的segments段，segments小于等于0时返回完整路径
+func trimPath(file string, segments int) string {
+	if segments <= 0 {
+		return file
+	}
+	idx := len(file)
+	for i := 0; i < segments; i++ {
+		idx = strings.LastIndex(file[:idx], "/")
+		if idx < 0 {
+			return file
 		}
 	}
+	return file[idx+1:]
+}
 
-	if len(prefix) > 0 {
-		prefix = fmt.Sprintf("%s ", prefix)
+// 通过flags形成前缀，color为true时等级标识着色
+// 各部分按时间、等级、组件名称、线程ID、调用信息的顺序组合，未设置的标识对应部分省略
+// FLAG_ALL与FLAG_NONE同样按此规则组合，无需单独处理
+func (l *Logger) formatPrefix(log logMsg, color bool) string {
+	// 时间与等级各自加括号，以分隔符连接，非空时末尾再加一个分隔符
+	var head []string
+	if log.flags&FLAG_TIME == FLAG_TIME {
+		head = append(head, l.bracket(log.time))
 	}
-	// 线程ID 协程
-	var goroutineId string
-	if logger.Flags&FLAG_THREADID == FLAG_THREADID {
-		goroutineId += fmt.Sprintf("[goId:%d] ", log.goID)
+	if log.flags&FLAG_LEVEL == FLAG_LEVEL {
+		level := l.textLevel(log.level)
+		if color {
+			level = colorize(log.level, level)
+		}
+		head = append(head, l.bracket(level))
 	}
-	// 获取调用函数信息
-	var funcInfo string
-	if logger.Flags&FLAG_FILENAME == FLAG_FILENAME {
-		funcInfo += log.fileName
+	var prefix string
+	if len(head) > 0 {
+		prefix = strings.Join(head, l.separator) + l.separator
 	}
 
-	if logger.Flags&FLAG_FUNCNAME == FLAG_FUNCNAME {
-		if len(funcInfo) > 0 {
-			funcInfo = " " + log.funcName + "()"
-		} else {
-			funcInfo += log.funcName + "()"
-		}
-	}
+	prefix += l.formatName(log.name)
 
-	if logger.Flags&FLAG_LINENO == FLAG_LINENO {
-		if len(funcInfo) > 0 {
-			funcInfo += " " + fmt.Sprintf("line%d", log.lineNo)
-		} else {
-			funcInfo += fmt.Sprintf("line%d", log.lineNo)
-		}
+	// 线程ID 协程
+	if log.flags&FLAG_THREADID == FLAG_THREADID {
+		prefix += l.formatGoId(log.goID)
 	}
 
-	if len(funcInfo) > 0 {
-		funcInfo = fmt.Sprintf("[%s] ", funcInfo)
+	// 文件名、函数名、行号以空格连接，合并在一个括号内
+	var caller []string
+	if log.flags&FLAG_FILENAME == FLAG_FILENAME {
+		caller = append(caller, log.fileName)
+	}
+	if log.flags&FLAG_FUNCNAME == FLAG_FUNCNAME {
+		caller = append(caller, log.funcName+"()")
+	}
+	if log.flags&FLAG_LINENO == FLAG_LINENO {
+		caller = append(caller, fmt.Sprintf("line%d", log.lineNo))
+	}
+	if len(caller) > 0 {
+		prefix += l.bracket(strings.Join(caller, " ")) + l.separator
 	}
 
-	return prefix + funcInfo + goroutineId
+	return prefix
 }
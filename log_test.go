@@ -0,0 +1,1215 @@
+package MyLog
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestOutPutDeliversAfterIdle(t *testing.T) {
+	l, buf := newTestLogger(t)
+	time.Sleep(50 * time.Millisecond)
+	l.Info("after idle")
+	l.Flush()
+	if got := lines(buf); len(got) != 1 || got[0] != "[INFO   ] after idle" {
+		t.Fatalf("got %q", got)
+	}
+}
+
+func TestFlagsHaveDistinctBits(t *testing.T) {
+	flags := []LogFlag{FLAG_TIME, FLAG_THREADID, FLAG_LEVEL, FLAG_FILENAME, FLAG_FUNCNAME, FLAG_LINENO}
+	var all LogFlag
+	for _, f := range flags {
+		if all&f != 0 {
+			t.Fatalf("flag %06b overlaps %06b", f, all)
+		}
+		all |= f
+	}
+	if all != FLAG_ALL {
+		t.Fatalf("FLAG_ALL = %06b, want %06b", FLAG_ALL, all)
+	}
+}
+
+func TestThreadIDWithoutLevel(t *testing.T) {
+	l, buf := newTestLogger(t, WithFlags(FLAG_THREADID))
+	l.Info("tid")
+	l.Flush()
+	line := buf.String()
+	if !regexp.MustCompile(`^\[goroutine \d+\] tid\n$`).MatchString(line) {
+		t.Fatalf("got %q", line)
+	}
+	if strings.Contains(line, "INFO") {
+		t.Fatalf("level should not appear: %q", line)
+	}
+}
+
+func TestGoroutineIDPerWorker(t *testing.T) {
+	l, buf := newTestLogger(t, WithFlags(FLAG_LEVEL|FLAG_THREADID))
+	ids := make(chan int, 2)
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ids <- getGoId()
+			l.Info("worker")
+		}()
+	}
+	wg.Wait()
+	close(ids)
+	l.Flush()
+	out := buf.String()
+	seen := map[int]bool{}
+	for id := range ids {
+		if id <= 0 || seen[id] {
+			t.Fatalf("bad goroutine id %d", id)
+		}
+		seen[id] = true
+		if want := fmt.Sprintf("[INFO   ] [goroutine %d] worker\n", id); !strings.Contains(out, want) {
+			t.Fatalf("missing %q in %q", want, out)
+		}
+	}
+}
+
+func TestGoroutineIDUnknown(t *testing.T) {
+	l, _ := newTestLogger(t)
+	if got := l.formatGoId(0); got != "" {
+		t.Fatalf("unknown id rendered as %q", got)
+	}
+}
+
+func TestFormattedLevels(t *testing.T) {
+	skipIfDebugStripped(t)
+	stubExit(t)
+	l, buf := newTestLogger(t)
+	l.Debugf("d=%d", 1)
+	l.Infof("i=%s", "x")
+	l.Warningf("w=%v", true)
+	l.Errorf("e=%.1f", 1.5)
+	l.Fatalf("f=%q", "q")
+	l.Flush()
+	want := []string{
+		"[DEBUG  ] d=1",
+		"[INFO   ] i=x",
+		"[WARNING] w=true",
+		"[ERROR  ] e=1.5",
+		`[FATAL  ] f="q"`,
+	}
+	if got := lines(buf); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("got %q", got)
+	}
+}
+
+func TestVariadicArgs(t *testing.T) {
+	l, buf := newTestLogger(t)
+	l.Info()
+	l.Info("single")
+	l.Info("user", 42, "logged in")
+	l.Info([]int{1, 2})
+	l.Flush()
+	want := []string{
+		"[INFO   ] ",
+		"[INFO   ] single",
+		"[INFO   ] user 42 logged in",
+		"[INFO   ] [1 2]",
+	}
+	if got := lines(buf); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("got %q", got)
+	}
+}
+
+func TestLevelFilter(t *testing.T) {
+	stubExit(t)
+	l, buf := newTestLogger(t, WithLevel(WARNING))
+	l.Debug("d")
+	l.Info("i")
+	l.Warning("w")
+	l.Error("e")
+	l.Fatal("f")
+	l.Flush()
+	want := []string{"[WARNING] w", "[ERROR  ] e", "[FATAL  ] f"}
+	if got := lines(buf); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("got %q", got)
+	}
+}
+
+func TestPackageLevelFilter(t *testing.T) {
+	buf := captureDefault(t)
+	SetLevel(WARNING)
+	Info("hidden")
+	Warning("shown")
+	Flush()
+	if got := lines(buf); len(got) != 1 || got[0] != "[WARNING] shown" {
+		t.Fatalf("got %q", got)
+	}
+}
+
+func TestSetGetLevel(t *testing.T) {
+	captureDefault(t)
+	for _, level := range []LevelLog{TRACE, DEBUG, INFO, WARNING, ERROR, FATAL} {
+		SetLevel(level)
+		if got := GetLevel(); got != level {
+			t.Fatalf("GetLevel() = %v, want %v", got, level)
+		}
+	}
+	SetLevel(FATAL + 1)
+	if got := GetLevel(); got != FATAL {
+		t.Fatalf("invalid level accepted: %v", got)
+	}
+}
+
+func TestFatalFlushesThenExits(t *testing.T) {
+	code := stubExit(t)
+	l, buf := newTestLogger(t)
+	l.Fatal("bye")
+	// 退出前消息已写出，无需再调用Flush
+	if *code != 1 || buf.String() != "[FATAL  ] bye\n" {
+		t.Fatalf("code %d, output %q", *code, buf.String())
+	}
+}
+
+func TestFatalSubprocess(t *testing.T) {
+	if os.Getenv("MYLOG_FATAL_CHILD") == "1" {
+		l := New(WithOutputType(ONLY_TERMINAL), WithFlags(FLAG_LEVEL), WithErrorToStderr(false))
+		l.Fatalf("exit %d", 1)
+		return
+	}
+	cmd := exec.Command(os.Args[0], "-test.run=^TestFatalSubprocess$")
+	cmd.Env = append(os.Environ(), "MYLOG_FATAL_CHILD=1")
+	out, err := cmd.Output()
+	exitErr, ok := err.(*exec.ExitError)
+	if !ok || exitErr.ExitCode() != 1 {
+		t.Fatalf("want exit code 1, got %v", err)
+	}
+	if !strings.Contains(string(out), "[FATAL  ] exit 1\n") {
+		t.Fatalf("fatal message lost: %q", out)
+	}
+}
+
+// 输出协程阻塞在第一条消息的写入上，之后的消息填满容量为2的通道
+func floodLogger(t *testing.T, policy OverflowPolicy) (*Logger, *gateWriter) {
+	t.Helper()
+	w := newGateWriter()
+	l, _ := newTestLogger(t, WithOutput(w), WithBufferSize(2), WithOverflowPolicy(policy), WithFlags(FLAG_NONE))
+	l.Info(1)
+	<-w.entered
+	return l, w
+}
+
+func TestOverflowDrop(t *testing.T) {
+	l, w := floodLogger(t, OVERFLOW_DROP)
+	for i := 2; i <= 6; i++ {
+		l.Info(i)
+	}
+	if got := l.DroppedCount(); got != 3 {
+		t.Fatalf("dropped %d, want 3", got)
+	}
+	close(w.release)
+	l.Flush()
+	if got := w.buf.String(); got != "1\n2\n3\n" {
+		t.Fatalf("got %q", got)
+	}
+}
+
+func TestOverflowDropOldest(t *testing.T) {
+	l, w := floodLogger(t, OVERFLOW_DROP_OLDEST)
+	for i := 2; i <= 6; i++ {
+		l.Info(i)
+	}
+	if got := l.DroppedCount(); got != 3 {
+		t.Fatalf("dropped %d, want 3", got)
+	}
+	close(w.release)
+	l.Flush()
+	if got := w.buf.String(); got != "1\n5\n6\n" {
+		t.Fatalf("got %q", got)
+	}
+}
+
+func TestOverflowBlock(t *testing.T) {
+	l, w := floodLogger(t, OVERFLOW_BLOCK)
+	done := make(chan struct{})
+	go func() {
+		for i := 2; i <= 6; i++ {
+			l.Info(i)
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+		t.Fatal("logging did not block on a full channel")
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(w.release)
+	<-done
+	l.Flush()
+	if got := w.buf.String(); got != "1\n2\n3\n4\n5\n6\n" || l.DroppedCount() != 0 {
+		t.Fatalf("got %q, dropped %d", got, l.DroppedCount())
+	}
+}
+
+func TestSetOutputRedirects(t *testing.T) {
+	l, first := newTestLogger(t)
+	l.Info("one")
+	l.Flush()
+	var second bytes.Buffer
+	l.SetOutput(&second)
+	l.Info("two")
+	l.Flush()
+	if first.String() != "[INFO   ] one\n" || second.String() != "[INFO   ] two\n" {
+		t.Fatalf("first %q, second %q", first.String(), second.String())
+	}
+}
+
+func TestAddRemoveWriter(t *testing.T) {
+	l, _ := newTestLogger(t)
+	var a, b bytes.Buffer
+	l.AddWriter(&a)
+	l.AddWriter(&b)
+	l.Info("both")
+	l.Warning("both again")
+	l.Flush()
+	l.RemoveWriter(&a)
+	l.Info("only b")
+	l.Flush()
+	if a.String() != "[INFO   ] both\n[WARNING] both again\n" {
+		t.Fatalf("a = %q", a.String())
+	}
+	if b.String() != a.String()+"[INFO   ] only b\n" {
+		t.Fatalf("b = %q", b.String())
+	}
+}
+
+func TestTimeFormatAndUTC(t *testing.T) {
+	zone := time.FixedZone("UTC+8", 8*3600)
+	at := time.Date(2024, 6, 1, 8, 30, 15, 123000000, zone)
+	l, buf := newTestLogger(t, WithFlags(FLAG_TIME|FLAG_LEVEL))
+	l.SetClock(func() time.Time { return at })
+	l.Info("default")
+	l.SetTimeFormat(time.RFC3339)
+	l.Info("rfc3339")
+	l.SetTimeUTC(true)
+	l.SetTimeFormat("2006-01-02 15:04:05.000 MST")
+	l.Info("utc")
+	l.Flush()
+	want := []string{
+		"[2024-06-01 08:30:15] [INFO   ] default",
+		"[2024-06-01T08:30:15+08:00] [INFO   ] rfc3339",
+		"[2024-06-01 00:30:15.123 UTC] [INFO   ] utc",
+	}
+	if got := lines(buf); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("got %q", got)
+	}
+}
+
+func TestIndependentLoggers(t *testing.T) {
+	access, accessBuf := newTestLogger(t)
+	errs, errsBuf := newTestLogger(t, WithLevel(ERROR))
+	access.Info("GET /")
+	errs.Info("hidden")
+	errs.Error("boom")
+	access.Flush()
+	errs.Flush()
+	if accessBuf.String() != "[INFO   ] GET /\n" || errsBuf.String() != "[ERROR  ] boom\n" {
+		t.Fatalf("access %q, errors %q", accessBuf.String(), errsBuf.String())
+	}
+	if access.GetLevel() != DEBUG {
+		t.Fatal("level leaked between loggers")
+	}
+}
+
+func TestCloseDrainsAndStops(t *testing.T) {
+	before := runtime.NumGoroutine()
+	var buf bytes.Buffer
+	l := New(WithOutputType(ONLY_TERMINAL), WithOutput(&buf), WithFlags(FLAG_NONE))
+	for i := 0; i < 100; i++ {
+		l.Info(i)
+	}
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(buf.String(), "\n"); n != 100 {
+		t.Fatalf("Close drained %d lines, want 100", n)
+	}
+	// 关闭后的日志被忽略，重复关闭和Flush不会阻塞
+	l.Info("after close")
+	l.Flush()
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(buf.String(), "after close") {
+		t.Fatal("logged after Close")
+	}
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if n := runtime.NumGoroutine(); n > before {
+		t.Fatalf("goroutines %d > %d before New", n, before)
+	}
+}
+
+func TestCloseClosesFile(t *testing.T) {
+	l, path := newFileLogger(t)
+	l.Info("x")
+	l.Close()
+	// 文件已关闭后可被删除，Windows下打开中的文件无法删除
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// 在-race下运行，设置函数与日志函数并发调用不应产生数据竞争
+func TestConcurrentSettersAndLoggers(t *testing.T) {
+	dir := t.TempDir()
+	l := New(WithOutputType(ONLY_TERMINAL), WithOutput(&syncBuffer{}), WithErrorOutput(&syncBuffer{}), WithFilePath(dir))
+	defer l.Close()
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; ; j++ {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				l.Info("worker", i, j)
+				l.Errorf("worker %d", i)
+			}
+		}(i)
+	}
+	for i := 0; i < 50; i++ {
+		l.SetLevel(LevelLog(i % 5))
+		l.SetFlags(LogFlag(i) & FLAG_ALL)
+		l.SetOutputType(OutputType(i%3 + 1))
+		if err := l.SetFileName(fmt.Sprintf("race%d.log", i%2)); err != nil {
+			t.Fatal(err)
+		}
+		l.SetLevelString(INFO, fmt.Sprint("I", i))
+		l.SetFormat(LogFormat(i % 3))
+	}
+	close(stop)
+	wg.Wait()
+	l.Flush()
+}
+
+func TestSetLevelStringAndReset(t *testing.T) {
+	l, buf := newTestLogger(t)
+	l.SetLevelString(INFO, "I")
+	l.SetLevelString(WARNING, "警告")
+	l.Info("short")
+	l.Warning("localized")
+	// 标识在输出时读取，先输出已提交的日志
+	l.Flush()
+	l.ResetLevelString()
+	l.Info("reset")
+	l.Flush()
+	want := []string{"[I] short", "[警告] localized", "[INFO   ] reset"}
+	if got := lines(buf); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("got %q", got)
+	}
+	if got := l.GetLevelString(WARNING); got != "WARNING" {
+		t.Fatalf("GetLevelString after reset = %q", got)
+	}
+}
+
+// 当前调用位置的行号
+func here() int {
+	_, _, line, _ := runtime.Caller(1)
+	return line
+}
+
+// 对日志函数再封装一层，配合SetCallerSkip(1)使用
+func wrappedInfo(l *Logger, msg string) {
+	l.Info(msg)
+}
+
+func TestCallerInfoAcrossPaths(t *testing.T) {
+	l, buf := newTestLogger(t, WithFlags(FLAG_FILENAME|FLAG_LINENO))
+	pkg := captureDefault(t)
+	SetFlags(FLAG_FILENAME | FLAG_LINENO)
+	var want, wantPkg []string
+	expect := func(line int, msg string) string { return fmt.Sprintf("[log_test.go line%d] %s", line, msg) }
+
+	l.Info("direct")
+	want = append(want, expect(here()-1, "direct"))
+	l.Infof("formatted %d", 1)
+	want = append(want, expect(here()-1, "formatted 1"))
+	l.SetCallerSkip(1)
+	wrappedInfo(l, "wrapped")
+	want = append(want, expect(here()-1, "wrapped"))
+	Info("package")
+	wantPkg = append(wantPkg, expect(here()-1, "package"))
+	Warningf("package %s", "f")
+	wantPkg = append(wantPkg, expect(here()-1, "package f"))
+
+	l.Flush()
+	Flush()
+	if got := lines(buf); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+	if got := lines(pkg); strings.Join(got, "\n") != strings.Join(wantPkg, "\n") {
+		t.Fatalf("package got %q, want %q", got, wantPkg)
+	}
+}
+
+// 不启动输出协程，直接从通道中取出提交的消息
+func submitted(t *testing.T, l *Logger, log func()) *logMsg {
+	t.Helper()
+	log()
+	select {
+	case m := <-l.msg:
+		return m
+	default:
+		return nil
+	}
+}
+
+func TestCallerSkippedWhenFlagsOff(t *testing.T) {
+	l := newLogger()
+	l.Flags = FLAG_LEVEL
+	m := submitted(t, l, func() { l.Info("x") })
+	if m == nil || m.fileName != "" || m.funcName != "" || m.lineNo != 0 || m.goID != 0 {
+		t.Fatalf("caller info captured with flags off: %+v", m)
+	}
+	l.Flags = FLAG_LINENO
+	if m = submitted(t, l, func() { l.Info("x") }); m == nil || m.lineNo == 0 {
+		t.Fatalf("caller info missing with FLAG_LINENO: %+v", m)
+	}
+	l.Level = ERROR
+	if m = submitted(t, l, func() { l.Info("x") }); m != nil {
+		t.Fatalf("filtered message submitted: %+v", m)
+	}
+}
+
+// 同步模式下输出到io.Discard，测量提交与格式化的开销
+func benchmarkInfo(b *testing.B, level LevelLog, flags LogFlag) {
+	l := New(WithOutputType(ONLY_TERMINAL), WithOutput(io.Discard), WithLevel(level), WithFlags(flags))
+	defer l.Close()
+	l.SetSync(true)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		l.Info("benchmark message")
+	}
+}
+
+func BenchmarkInfoCallerFlags(b *testing.B) {
+	benchmarkInfo(b, DEBUG, FLAG_TIME|FLAG_LEVEL|FLAG_FILENAME|FLAG_FUNCNAME|FLAG_LINENO)
+}
+
+func BenchmarkInfoNoCallerFlags(b *testing.B) {
+	benchmarkInfo(b, DEBUG, FLAG_TIME|FLAG_LEVEL)
+}
+
+func BenchmarkInfoFiltered(b *testing.B) {
+	benchmarkInfo(b, ERROR, FLAG_ALL)
+}
+
+func TestFileNameAndFuncName(t *testing.T) {
+	l, buf := newTestLogger(t, WithFlags(FLAG_FILENAME|FLAG_FUNCNAME))
+	l.Info("both")
+	l.Flush()
+	if got := buf.String(); got != "[log_test.go TestFileNameAndFuncName()] both\n" {
+		t.Fatalf("got %q", got)
+	}
+}
+
+func TestPathModes(t *testing.T) {
+	_, file, _, _ := runtime.Caller(0)
+	dir := filepath.Base(filepath.Dir(file))
+	l, buf := newTestLogger(t, WithFlags(FLAG_FILENAME))
+	l.Info("base")
+	l.SetFullPath(true)
+	l.Info("full")
+	l.SetPathSegments(2)
+	l.Info("two")
+	l.Flush()
+	want := []string{
+		"[log_test.go] base",
+		"[" + file + "] full",
+		"[" + dir + "/log_test.go] two",
+	}
+	if got := lines(buf); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+func TestTrimPath(t *testing.T) {
+	tests := []struct {
+		file     string
+		segments int
+		want     string
+	}{
+		{"/a/b/c.go", 0, "/a/b/c.go"},
+		{"/a/b/c.go", 1, "c.go"},
+		{"/a/b/c.go", 2, "b/c.go"},
+		{"/a/b/c.go", 3, "a/b/c.go"},
+		{"/a/b/c.go", 9, "/a/b/c.go"},
+		{"c.go", 1, "c.go"},
+	}
+	for _, tt := range tests {
+		if got := trimPath(tt.file, tt.segments); got != tt.want {
+			t.Errorf("trimPath(%q, %d) = %q, want %q", tt.file, tt.segments, got, tt.want)
+		}
+	}
+}
+
+func TestParseOutputType(t *testing.T) {
+	valid := map[string]OutputType{
+		"terminal": ONLY_TERMINAL,
+		"FILE":     ONLY_FILE,
+		" Both ":   BOTH_TERMINAL_AND_FILE,
+	}
+	for name, want := range valid {
+		if got, err := ParseOutputType(name); err != nil || got != want {
+			t.Errorf("ParseOutputType(%q) = %v, %v", name, got, err)
+		}
+	}
+	for _, name := range []string{"", "stdout", "files"} {
+		if _, err := ParseOutputType(name); err == nil {
+			t.Errorf("ParseOutputType(%q) accepted", name)
+		}
+	}
+	l, _ := newTestLogger(t)
+	if err := l.SetOutputTypeByName("nope"); err == nil || l.OutputType != ONLY_TERMINAL {
+		t.Fatalf("invalid name changed output type: %v", err)
+	}
+	if err := l.SetOutputTypeByName("both"); err != nil || l.OutputType != BOTH_TERMINAL_AND_FILE {
+		t.Fatalf("SetOutputTypeByName(both): %v", err)
+	}
+}
+
+func TestWithFlagsOverridesOneCall(t *testing.T) {
+	l, buf := newTestLogger(t, WithFlags(FLAG_LEVEL|FLAG_FUNCNAME))
+	l.InfoWithFlags(FLAG_NONE, "=== banner ===")
+	l.WarningWithFlags(FLAG_LEVEL, "level only")
+	l.Info("normal")
+	l.Flush()
+	want := []string{
+		"=== banner ===",
+		"[WARNING] level only",
+		"[INFO   ] [TestWithFlagsOverridesOneCall()] normal",
+	}
+	if got := lines(buf); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("got %q", got)
+	}
+	if l.Flags != FLAG_LEVEL|FLAG_FUNCNAME {
+		t.Fatalf("global flags changed to %06b", l.Flags)
+	}
+}
+
+func TestEachLineIsOneWrite(t *testing.T) {
+	term, extra := &recordWriter{}, &recordWriter{}
+	l, path := newFileLogger(t, WithOutputType(BOTH_TERMINAL_AND_FILE), WithOutput(term), WithErrorToStderr(false), WithFlags(FLAG_LEVEL|FLAG_THREADID))
+	l.AddWriter(extra)
+	l.SetSync(true)
+	line := regexp.MustCompile(`^\[INFO   \] \[goroutine \d+\] worker \d+ msg \d+ x+\n$`)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				l.Info("worker", i, "msg", j, strings.Repeat("x", 200))
+			}
+		}(i)
+	}
+	wg.Wait()
+	l.Flush()
+	for name, w := range map[string]*recordWriter{"terminal": term, "writer": extra} {
+		writes := w.Writes()
+		if len(writes) != 800 {
+			t.Fatalf("%s got %d writes, want 800", name, len(writes))
+		}
+		for _, s := range writes {
+			if !line.MatchString(s) {
+				t.Fatalf("%s got partial line %q", name, s)
+			}
+		}
+	}
+	for _, s := range strings.SplitAfter(readFile(t, path), "\n") {
+		if s != "" && !line.MatchString(s) {
+			t.Fatalf("file has garbled line %q", s)
+		}
+	}
+}
+
+func TestSetEnabled(t *testing.T) {
+	l, buf := newTestLogger(t)
+	var hooked int
+	l.AddHook(TRACE, func(LevelLog, string) { hooked++ })
+	l.SetEnabled(false)
+	l.Info("silent")
+	l.Errorf("silent %d", 1)
+	if l.IsEnabled(ERROR) {
+		t.Fatal("IsEnabled true while disabled")
+	}
+	l.SetEnabled(true)
+	l.Info("back")
+	l.Flush()
+	if buf.String() != "[INFO   ] back\n" || hooked != 1 {
+		t.Fatalf("output %q, hooks %d", buf.String(), hooked)
+	}
+}
+
+func BenchmarkInfoDisabled(b *testing.B) {
+	l := New(WithOutputType(ONLY_TERMINAL), WithOutput(io.Discard))
+	defer l.Close()
+	l.SetEnabled(false)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		l.Info("benchmark message")
+	}
+}
+
+func TestNoStdoutFromPackage(t *testing.T) {
+	if os.Getenv("MYLOG_STDOUT_CHILD") == "1" {
+		// 导入包、初始化默认日志对象时不应向标准输出打印，栈深度不足时也不提示
+		getFuncCallerInfo(1000, 1)
+		l := New(WithOutputType(ONLY_TERMINAL), WithOutput(io.Discard), WithErrorOutput(io.Discard))
+		l.SetCallerSkip(1000)
+		l.Info("deep")
+		l.Close()
+		return
+	}
+	cmd := exec.Command(os.Args[0], "-test.run=^TestNoStdoutFromPackage$")
+	cmd.Env = append(os.Environ(), "MYLOG_STDOUT_CHILD=1")
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 子进程只应输出测试框架自身的PASS
+	if got := strings.TrimSpace(string(out)); got != "PASS" {
+		t.Fatalf("unexpected stdout %q", got)
+	}
+	if file, fn, line := getFuncCallerInfo(1000, 1); file != "" || fn != "" || line != 0 {
+		t.Fatalf("caller info for an out-of-range skip: %q %q %d", file, fn, line)
+	}
+}
+
+func TestLazyFuncSkippedWhenFiltered(t *testing.T) {
+	l, buf := newTestLogger(t)
+	l.SetLevel(ERROR)
+	var called int32
+	fn := func() string {
+		atomic.AddInt32(&called, 1)
+		return "expensive"
+	}
+	l.DebugFunc(fn)
+	l.InfoFunc(fn)
+	l.WarningFunc(fn)
+	l.Flush()
+	// 等级被过滤时fn不应被调用
+	if n := atomic.LoadInt32(&called); n != 0 {
+		t.Fatalf("fn called %d times while filtered", n)
+	}
+	l.ErrorFunc(fn)
+	l.Flush()
+	if n := atomic.LoadInt32(&called); n != 1 {
+		t.Fatalf("fn called %d times, want 1", n)
+	}
+	if got := buf.String(); got != "[ERROR  ] expensive\n" {
+		t.Fatalf("got %q", got)
+	}
+}
+
+func TestErrorToStderrRouting(t *testing.T) {
+	skipIfDebugStripped(t)
+	var errBuf bytes.Buffer
+	l, out := newTestLogger(t, WithErrorToStderr(true), WithErrorOutput(&errBuf))
+	l.Debug("d")
+	l.Info("i")
+	l.Warning("w")
+	l.Error("e")
+	l.Flush()
+	// WARNING及以上写入错误输出，其余写入标准输出
+	if got := out.String(); got != "[DEBUG  ] d\n[INFO   ] i\n" {
+		t.Fatalf("stdout got %q", got)
+	}
+	if got := errBuf.String(); got != "[WARNING] w\n[ERROR  ] e\n" {
+		t.Fatalf("stderr got %q", got)
+	}
+
+	l.SetErrorToStderr(false)
+	l.Error("all")
+	l.Flush()
+	if !strings.HasSuffix(out.String(), "[ERROR  ] all\n") {
+		t.Fatalf("stdout got %q after disabling", out.String())
+	}
+}
+
+func TestNamedLoggers(t *testing.T) {
+	l, buf := newTestLogger(t)
+	l.SetLevel(INFO)
+	auth := l.Named("auth")
+	db := l.Named("db")
+	auth.Debug("filtered")
+	auth.Info("login")
+	db.SetLevel(WARNING)
+	db.Info("filtered")
+	db.Warning("slow")
+	auth.Named("token").Info("issued")
+	l.Info("root")
+	l.Flush()
+	// 子对象继承父对象的等级，修改子对象的等级不影响父对象和其他子对象
+	want := "[INFO   ] [auth] login\n[WARNING] [db] slow\n[INFO   ] [auth.token] issued\n[INFO   ] root\n"
+	if got := buf.String(); got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+func TestSetEnabledAffectsDerived(t *testing.T) {
+	l, buf := newTestLogger(t)
+	named := l.Named("auth")
+	with := l.With(Fields{"k": 1})
+	l.SetEnabled(false)
+	// 父对象关闭后子对象也不再输出
+	named.Info("a")
+	with.Info("b")
+	if named.IsEnabled(FATAL) || with.IsEnabled(FATAL) {
+		t.Fatal("derived logger enabled while root disabled")
+	}
+	l.Flush()
+	if buf.Len() != 0 {
+		t.Fatalf("got %q while disabled", buf.String())
+	}
+
+	l.SetEnabled(true)
+	// 关闭子对象只影响该子对象
+	named.SetEnabled(false)
+	named.Info("c")
+	with.Info("d")
+	l.Flush()
+	if got := buf.String(); got != "[INFO   ] k=1 d\n" {
+		t.Fatalf("got %q", got)
+	}
+}
+
+func TestTrimFuncName(t *testing.T) {
+	tests := []struct{ name, want string }{
+		{"main.run", "run"},
+		{"github.com/a/b.c/pkg.Func", "Func"},
+		{"github.com/a/pkg.T.Method", "T.Method"},
+		{"github.com/a/pkg.(*T).Method", "(*T).Method"},
+		{"github.com/a/pkg.Func.func1", "Func.func1"},
+		{"github.com/a/pkg.(*T).Method.func2.1", "(*T).Method.func2.1"},
+	}
+	for _, tt := range tests {
+		if got := trimFuncName(tt.name); got != tt.want {
+			t.Errorf("trimFuncName(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+// 用于测试方法名的调用信息
+type callerType struct{ l *Logger }
+
+func (c callerType) value()    { c.l.Info("v") }
+func (c *callerType) pointer() { c.l.Info("p") }
+
+func TestFuncNameForMethodsAndClosures(t *testing.T) {
+	l, buf := newTestLogger(t, WithFlags(FLAG_FUNCNAME))
+	c := &callerType{l: l}
+	c.value()
+	c.pointer()
+	func() { l.Info("c") }()
+	l.Flush()
+	want := []string{
+		"[callerType.value()] v",
+		"[(*callerType).pointer()] p",
+		"[TestFuncNameForMethodsAndClosures.func1()] c",
+	}
+	if got := lines(buf); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+func TestSyncModeWritesImmediately(t *testing.T) {
+	var buf syncBuffer
+	l, _ := newTestLogger(t, WithOutput(&buf))
+	l.SetSync(true)
+	// 同步模式下调用返回时已输出，无需Flush
+	l.Info("now")
+	if got := buf.String(); got != "[INFO   ] now\n" {
+		t.Fatalf("got %q", got)
+	}
+	l.SetSync(false)
+	l.Info("later")
+	l.Flush()
+	if got := buf.String(); got != "[INFO   ] now\n[INFO   ] later\n" {
+		t.Fatalf("got %q", got)
+	}
+}
+
+func TestLevelStringConcurrentUpdate(t *testing.T) {
+	var buf syncBuffer
+	l, _ := newTestLogger(t, WithOutput(&buf))
+	const n = 200
+	var wg sync.WaitGroup
+	wg.Add(2)
+	// 在-race下运行，输出协程读取标识的同时修改标识
+	go func() {
+		defer wg.Done()
+		for i := 0; i < n; i++ {
+			if i%2 == 0 {
+				l.SetLevelString(INFO, "I")
+			} else {
+				l.SetLevelString(INFO, "INF")
+			}
+			if i%50 == 0 {
+				l.ResetLevelString()
+			}
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < n; i++ {
+			l.Info(i)
+			_ = l.GetLevelString(INFO)
+		}
+	}()
+	wg.Wait()
+	l.Flush()
+	for _, line := range strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n") {
+		if !strings.HasPrefix(line, "[I] ") && !strings.HasPrefix(line, "[INF] ") && !strings.HasPrefix(line, "[INFO   ] ") {
+			t.Fatalf("unexpected label in %q", line)
+		}
+	}
+}
+
+func TestStackTraceAtErrorLevel(t *testing.T) {
+	l, buf := newTestLogger(t)
+	l.SetStackTrace(ERROR)
+	l.Warning("no stack")
+	l.Error("with stack")
+	l.Flush()
+	out := buf.String()
+	warn, errLog, _ := strings.Cut(out, "[ERROR  ] with stack\n")
+	if warn != "[WARNING] no stack\n" {
+		t.Fatalf("warning got %q", warn)
+	}
+	// 调用栈从调用日志函数处开始，不含MyLog内部的栈帧
+	if !strings.Contains(errLog, "TestStackTraceAtErrorLevel") {
+		t.Fatalf("stack missing test function: %q", errLog)
+	}
+	if strings.Contains(errLog, "submit") || strings.Contains(errLog, "handleLogMsg") {
+		t.Fatalf("stack contains logger internals: %q", errLog)
+	}
+}
+
+func TestLevelCount(t *testing.T) {
+	l, _ := newTestLogger(t)
+	l.SetLevel(INFO)
+	l.Debug("filtered")
+	for i := 0; i < 3; i++ {
+		l.Info(i)
+	}
+	l.Warning("w")
+	l.Named("db").Error("e")
+	l.With(Fields{"k": 1}).Error("e")
+	// 子对象与父对象共享计数，被过滤的消息不计入
+	want := map[LevelLog]uint64{TRACE: 0, DEBUG: 0, INFO: 3, WARNING: 1, ERROR: 2, FATAL: 0}
+	for level, n := range want {
+		if got := l.LevelCount(level); got != n {
+			t.Errorf("LevelCount(%v) = %d, want %d", level, got, n)
+		}
+	}
+	if got := l.LevelCount(FATAL + 1); got != 0 {
+		t.Errorf("LevelCount(out of range) = %d", got)
+	}
+}
+
+func TestIsEnabledBoundaries(t *testing.T) {
+	skipIfDebugStripped(t)
+	l, _ := newTestLogger(t)
+	levels := []LevelLog{TRACE, DEBUG, INFO, WARNING, ERROR, FATAL}
+	for _, threshold := range levels {
+		l.SetLevel(threshold)
+		for _, level := range levels {
+			if got, want := l.IsEnabled(level), level >= threshold; got != want {
+				t.Errorf("level %v, threshold %v: IsEnabled = %v, want %v", level, threshold, got, want)
+			}
+		}
+	}
+	// 关闭日志后任何等级都不输出
+	l.SetLevel(TRACE)
+	l.SetEnabled(false)
+	if l.IsEnabled(FATAL) {
+		t.Fatal("IsEnabled(FATAL) while disabled")
+	}
+}
+
+func TestReleaseMsgClearsFields(t *testing.T) {
+	log := logMsgPool.Get().(*logMsg)
+	log.msg = "secret"
+	log.fields = []field{{key: "k", value: 1}}
+	log.level = ERROR
+	releaseMsg(log)
+	// 放回池中的消息不保留上一条日志的内容
+	if log.msg != "" || log.fields != nil || log.level != TRACE {
+		t.Fatalf("released message kept %+v", *log)
+	}
+}
+
+func BenchmarkInfoAsync(b *testing.B) {
+	l := New(WithOutputType(ONLY_TERMINAL), WithOutput(io.Discard), WithFlags(FLAG_TIME|FLAG_LEVEL), WithOverflowPolicy(OVERFLOW_BLOCK))
+	defer l.Close()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		l.Info("benchmark message")
+	}
+	l.Flush()
+}
+
+// 经通道传递的消息：复用与每次分配的对比
+func BenchmarkLogMsgPooled(b *testing.B) {
+	ch := make(chan *logMsg, 1)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		log := logMsgPool.Get().(*logMsg)
+		log.msg = "benchmark message"
+		ch <- log
+		releaseMsg(<-ch)
+	}
+}
+
+func BenchmarkLogMsgAlloc(b *testing.B) {
+	ch := make(chan *logMsg, 1)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		ch <- &logMsg{msg: "benchmark message"}
+		<-ch
+	}
+}
+
+// 格式化一行日志的缓冲：复用与每次分配的对比
+func BenchmarkLinePooled(b *testing.B) {
+	content := strings.Repeat("x", 100)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		buf := linePool.Get().(*[]byte)
+		line := append(append((*buf)[:0], content...), '\n')
+		io.Discard.Write(line)
+		putLine(buf, line)
+	}
+}
+
+func BenchmarkLineFresh(b *testing.B) {
+	content := strings.Repeat("x", 100)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		io.Discard.Write([]byte(content + "\n"))
+	}
+}
+
+func TestElapsedTimeMode(t *testing.T) {
+	l, buf := newTestLogger(t, WithFlags(FLAG_TIME))
+	l.SetTimeMode(TIME_ELAPSED)
+	l.Info("a")
+	time.Sleep(30 * time.Millisecond)
+	l.Info("b")
+	l.Flush()
+	re := regexp.MustCompile(`^\[\+(\d+\.\d{4})s\] [ab]$`)
+	var elapsed []float64
+	for _, line := range lines(buf) {
+		m := re.FindStringSubmatch(line)
+		if m == nil {
+			t.Fatalf("unexpected line %q", line)
+		}
+		v, _ := strconv.ParseFloat(m[1], 64)
+		elapsed = append(elapsed, v)
+	}
+	// 两行之间至少相差休眠的时间
+	if len(elapsed) != 2 || elapsed[1]-elapsed[0] < 0.0299 {
+		t.Fatalf("elapsed %v", elapsed)
+	}
+}
+
+func TestSetCallerOff(t *testing.T) {
+	l := newLogger()
+	l.Flags = FLAG_ALL
+	l.SetCaller(false)
+	// 关闭后不论flags如何都不获取调用信息，输出中也不含对应部分
+	m := submitted(t, l, func() { l.Info("x") })
+	if m == nil || m.fileName != "" || m.funcName != "" || m.lineNo != 0 {
+		t.Fatalf("caller info captured with caller off: %+v", m)
+	}
+	if m.flags&(FLAG_FILENAME|FLAG_FUNCNAME|FLAG_LINENO) != 0 {
+		t.Fatalf("caller flags kept: %08b", m.flags)
+	}
+
+	out, buf := newTestLogger(t, WithFlags(FLAG_LEVEL|FLAG_FILENAME|FLAG_LINENO))
+	out.SetCaller(false)
+	out.Info("off")
+	out.SetCaller(true)
+	out.Info("on")
+	out.Flush()
+	got := lines(buf)
+	if len(got) != 2 || got[0] != "[INFO   ] off" || !strings.HasPrefix(got[1], "[INFO   ] [log_test.go line") {
+		t.Fatalf("got %q", got)
+	}
+}
+
+// 同步模式下输出全部调用信息字段，比较开启与关闭调用信息的开销
+func benchmarkCaller(b *testing.B, enable bool) {
+	l := New(WithOutputType(ONLY_TERMINAL), WithOutput(io.Discard), WithFlags(FLAG_TIME|FLAG_LEVEL|FLAG_FILENAME|FLAG_FUNCNAME|FLAG_LINENO))
+	defer l.Close()
+	l.SetSync(true)
+	l.SetCaller(enable)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		l.Info("benchmark message")
+	}
+}
+
+func BenchmarkCallerOn(b *testing.B) {
+	benchmarkCaller(b, true)
+}
+
+func BenchmarkCallerOff(b *testing.B) {
+	benchmarkCaller(b, false)
+}
+
+func TestLevelStringAndParse(t *testing.T) {
+	for level := TRACE; level <= FATAL; level++ {
+		got, err := ParseLevel(level.String())
+		if err != nil || got != level {
+			t.Errorf("ParseLevel(%q) = %v, %v", level.String(), got, err)
+		}
+		// 不区分大小写，忽略首尾空白
+		if got, err := ParseLevel(" " + strings.ToLower(level.String()) + "\n"); err != nil || got != level {
+			t.Errorf("ParseLevel lower %q = %v, %v", level.String(), got, err)
+		}
+	}
+	if got, err := ParseLevel("warn"); err != nil || got != WARNING {
+		t.Errorf("ParseLevel(warn) = %v, %v", got, err)
+	}
+	for _, name := range []string{"", "verbose", "INFOO", "LEVEL9"} {
+		if _, err := ParseLevel(name); err == nil {
+			t.Errorf("ParseLevel(%q) returned nil error", name)
+		}
+	}
+	if got := fmt.Sprint(ERROR); got != "ERROR" {
+		t.Errorf("fmt.Sprint(ERROR) = %q", got)
+	}
+	if got := LevelLog(42).String(); got != "LEVEL42" {
+		t.Errorf("LevelLog(42).String() = %q", got)
+	}
+}
+
+func TestFixedClock(t *testing.T) {
+	clock := newFakeClock(time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC))
+	l, buf := newTestLogger(t, WithFlags(FLAG_TIME|FLAG_LEVEL))
+	l.SetClock(clock.Now)
+	l.Info("a")
+	clock.Add(90 * time.Minute)
+	l.Info("b")
+	l.SetClock(nil)
+	l.Info("real")
+	l.Flush()
+	got := lines(buf)
+	// 固定时钟下时间戳精确可预期，传nil恢复系统时钟
+	if len(got) != 3 || got[0] != "[2030-01-02 03:04:05] [INFO   ] a" || got[1] != "[2030-01-02 04:34:05] [INFO   ] b" {
+		t.Fatalf("got %q", got)
+	}
+	if strings.HasPrefix(got[2], "[2030-") {
+		t.Fatalf("clock not restored: %q", got[2])
+	}
+}
+
+func TestShouldFail(t *testing.T) {
+	l, _ := newTestLogger(t)
+	l.Error("not counted yet")
+	if l.ShouldFail() {
+		t.Fatal("ShouldFail without SetFailOnLevel")
+	}
+	l.SetFailOnLevel(ERROR)
+	if !l.ShouldFail() {
+		t.Fatal("ShouldFail false after an ERROR was logged")
+	}
+
+	l2, _ := newTestLogger(t)
+	l2.SetFailOnLevel(ERROR)
+	l2.Warning("w")
+	if l2.ShouldFail() {
+		t.Fatal("WARNING below the threshold made ShouldFail true")
+	}
+	// 子对象共享计数与判定等级
+	l2.With(Fields{"k": 1}).Error("e")
+	if !l2.ShouldFail() {
+		t.Fatal("ERROR from a child logger not counted")
+	}
+}
+
+func TestExitOnFail(t *testing.T) {
+	code := stubExit(t)
+	l, buf := newTestLogger(t)
+	l.SetFailOnLevel(WARNING)
+	l.Info("i")
+	l.ExitOnFail()
+	if *code != -1 {
+		t.Fatalf("exited with %d without a failure", *code)
+	}
+	l.Warning("last")
+	l.ExitOnFail()
+	if *code != 1 {
+		t.Fatalf("exit code %d, want 1", *code)
+	}
+	// 退出前已输出剩余日志
+	if !strings.Contains(buf.String(), "last") {
+		t.Fatalf("not flushed before exit: %q", buf.String())
+	}
+}
+
+// 遍历全部2^6种标识组合，各部分按固定顺序出现，未设置的部分省略
+func TestFormatPrefixAllFlagCombinations(t *testing.T) {
+	l, _ := newTestLogger(t)
+	msg := logMsg{level: INFO, time: "T", fileName: "a.go", funcName: "f", lineNo: 3, goID: 7}
+	for flags := FLAG_NONE; flags <= FLAG_ALL; flags++ {
+		var want string
+		if flags&FLAG_TIME != 0 {
+			want += "[T] "
+		}
+		if flags&FLAG_LEVEL != 0 {
+			want += "[INFO   ] "
+		}
+		if flags&FLAG_THREADID != 0 {
+			want += "[goroutine 7] "
+		}
+		var caller []string
+		if flags&FLAG_FILENAME != 0 {
+			caller = append(caller, "a.go")
+		}
+		if flags&FLAG_FUNCNAME != 0 {
+			caller = append(caller, "f()")
+		}
+		if flags&FLAG_LINENO != 0 {
+			caller = append(caller, "line3")
+		}
+		if len(caller) > 0 {
+			want += "[" + strings.Join(caller, " ") + "] "
+		}
+		msg.flags = flags
+		if got := l.formatPrefix(msg, false); got != want {
+			t.Errorf("flags %06b: got %q, want %q", flags, got, want)
+		}
+	}
+}
@@ -0,0 +1,30 @@
+//go:build unix
+
+package MyLog
+
+import (
+	"syscall"
+	"testing"
+	"time"
+)
+
+// 进程已消耗的CPU时间
+func cpuTime(t *testing.T) time.Duration {
+	var ru syscall.Rusage
+	if err := syscall.Getrusage(syscall.RUSAGE_SELF, &ru); err != nil {
+		t.Fatal(err)
+	}
+	return time.Duration(ru.Utime.Nano() + ru.Stime.Nano())
+}
+
+func TestOutPutIdleDoesNotSpin(t *testing.T) {
+	l, _ := newTestLogger(t)
+	l.Info("warm up")
+	l.Flush()
+
+	before := cpuTime(t)
+	time.Sleep(500 * time.Millisecond)
+	if used := cpuTime(t) - before; used > 100*time.Millisecond {
+		t.Fatalf("idle logger used %v CPU in 500ms", used)
+	}
+}
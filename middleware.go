@@ -0,0 +1,71 @@
+package MyLog
+
+import (
+	"net/http"
+	"runtime"
+	"time"
+)
+
+// 记录响应状态码的ResponseWriter
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+// 记录状态码后写入响应头
+func (r *statusRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// 未调用WriteHeader直接写入时状态码为200
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	return r.ResponseWriter.Write(b)
+}
+
+// 返回原始的ResponseWriter，供http.ResponseController使用
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// HTTP访问日志中间件，每个请求处理完成后以INFO等级输出method、path、status、duration字段
+// 日志在net/http的协程中输出，调用信息记录为调用Middleware处，即注册该中间件的位置
+func (l *Logger) Middleware(next http.Handler) http.Handler {
+	return l.middleware(next, callerPC())
+}
+
+// 同Logger.Middleware，作用于默认日志对象
+func Middleware(next http.Handler) http.Handler {
+	return logger.middleware(next, callerPC())
+}
+
+// 获取调用Middleware处的pc
+func callerPC() uintptr {
+	var pcs [1]uintptr
+	// 跳过runtime.Callers、callerPC和Middleware
+	runtime.Callers(3, pcs[:])
+	return pcs[0]
+}
+
+// 创建访问日志中间件，pc为日志记录的调用位置
+func (l *Logger) middleware(next http.Handler, pc uintptr) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w}
+		next.ServeHTTP(rec, r)
+		if rec.status == 0 {
+			rec.status = http.StatusOK
+		}
+		l.submit(nil, INFO, nil, "request", []field{
+			{key: "method", value: r.Method},
+			{key: "path", value: r.URL.Path},
+			{key: "status", value: rec.status},
+			{key: "duration", value: time.Since(start)},
+		}, pc)
+	})
+}
@@ -0,0 +1,53 @@
+package MyLog
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"runtime"
+	"strconv"
+	"testing"
+)
+
+func TestMiddlewareLogsRequest(t *testing.T) {
+	l, buf := newTestLogger(t, WithFlags(FLAG_LEVEL|FLAG_FILENAME|FLAG_FUNCNAME|FLAG_LINENO))
+	_, _, line, _ := runtime.Caller(0)
+	h := l.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	for _, path := range []string{"/ok", "/missing"} {
+		req := httptest.NewRequest(http.MethodPost, path, nil)
+		h.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	l.Flush()
+	// 调用信息为注册中间件的位置，而不是net/http内部
+	caller := `\[middleware_test.go TestMiddlewareLogsRequest\(\) line` + strconv.Itoa(line+1) + `\]`
+	want := []*regexp.Regexp{
+		regexp.MustCompile(`^\[INFO   \] ` + caller + ` method=POST path=/ok status=200 duration=\S+ request$`),
+		regexp.MustCompile(`^\[INFO   \] ` + caller + ` method=POST path=/missing status=404 duration=\S+ request$`),
+	}
+	got := lines(buf)
+	if len(got) != len(want) {
+		t.Fatalf("got %q", got)
+	}
+	for i, re := range want {
+		if !re.MatchString(got[i]) {
+			t.Errorf("line %d = %q, want match %s", i, got[i], re)
+		}
+	}
+}
+
+func TestPackageMiddlewareCaller(t *testing.T) {
+	buf := captureDefault(t)
+	SetFlags(FLAG_FILENAME | FLAG_FUNCNAME)
+	h := Middleware(http.NotFoundHandler())
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	Flush()
+	if got := buf.String(); !regexp.MustCompile(`^\[middleware_test.go TestPackageMiddlewareCaller\(\)\] method=GET path=/ status=404 `).MatchString(got) {
+		t.Fatalf("got %q", got)
+	}
+}
@@ -0,0 +1,136 @@
+package MyLog
+
+import (
+	"io"
+	"path/filepath"
+)
+
+// 日志对象配置项，用于New创建日志对象
+// 配置项在输出协程启动前执行，直接修改字段，不能调用需要输出协程的方法
+type Option func(*Logger)
+
+// 设置日志等级，超出TRACE~FATAL范围的值将被忽略
+func WithLevel(level LevelLog) Option {
+	return func(l *Logger) {
+		if level <= FATAL {
+			l.Level = level
+		}
+	}
+}
+
+// 设置输出字段
+func WithFlags(flags LogFlag) Option {
+	return func(l *Logger) {
+		l.Flags = flags
+	}
+}
+
+// 设置输出类型
+func WithOutputType(outputType OutputType) Option {
+	return func(l *Logger) {
+		l.OutputType = outputType
+	}
+}
+
+// 设置终端输出目标
+func WithOutput(w io.Writer) Option {
+	return func(l *Logger) {
+		l.terminal = w
+		if !l.colorForced {
+			l.color = isTerminal(w)
+		}
+	}
+}
+
+// 设置终端的错误输出目标
+func WithErrorOutput(w io.Writer) Option {
+	return func(l *Logger) {
+		l.errTerminal = w
+		if !l.colorForced {
+			l.errColor = isTerminal(w)
+		}
+	}
+}
+
+// 设置终端输出时WARNING及以上等级是否写入错误输出
+func WithErrorToStderr(enable bool) Option {
+	return func(l *Logger) {
+		l.errorToStderr = enable
+	}
+}
+
+// 设置日志文件，file可以包含目录，如logs/app.log
+func WithFile(file string) Option {
+	return func(l *Logger) {
+		dir, name := filepath.Split(file)
+		if dir != "" {
+			l.filePath = dir
+		}
+		l.fileName = name
+	}
+}
+
+// 设置日志文件所在目录
+func WithFilePath(dir string) Option {
+	return func(l *Logger) {
+		l.filePath = dir
+	}
+}
+
+// 设置输出格式
+func WithFormat(format LogFormat) Option {
+	return func(l *Logger) {
+		l.format = format
+	}
+}
+
+// 使用JSON格式输出，等同于WithFormat(FORMAT_JSON)
+func WithJSON() Option {
+	return WithFormat(FORMAT_JSON)
+}
+
+// 设置时间格式
+func WithTimeFormat(layout string) Option {
+	return func(l *Logger) {
+		l.timeFormat = layout
+	}
+}
+
+// 设置是否使用UTC时间
+func WithTimeUTC(utc bool) Option {
+	return func(l *Logger) {
+		l.timeUTC = utc
+	}
+}
+
+// 设置终端输出是否着色
+func WithColor(enable bool) Option {
+	return func(l *Logger) {
+		l.color = enable
+		l.errColor = enable
+		l.colorForced = true
+	}
+}
+
+// 设置通道满时的处理策略
+func WithOverflowPolicy(policy OverflowPolicy) Option {
+	return func(l *Logger) {
+		l.overflow = policy
+	}
+}
+
+// 设置获取调用信息时额外跳过的栈帧数
+func WithCallerSkip(n int) Option {
+	return func(l *Logger) {
+		l.callerSkip = n
+	}
+}
+
+// 设置存储日志消息的通道容量，默认为1000；通道在创建时确定，创建后无法修改
+func WithBufferSize(n int) Option {
+	return func(l *Logger) {
+		if n >= 0 {
+			l.msg = make(chan *logMsg, n)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package MyLog
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestNewFromOptions(t *testing.T) {
+	dir := t.TempDir()
+	var out, errOut bytes.Buffer
+	l := New(
+		WithLevel(INFO),
+		WithFlags(FLAG_TIME|FLAG_LEVEL),
+		WithOutputType(ONLY_TERMINAL),
+		WithOutput(&out),
+		WithErrorOutput(&errOut),
+		WithErrorToStderr(false),
+		WithFile(filepath.Join(dir, "app.log")),
+		WithJSON(),
+		WithTimeFormat(time.RFC3339),
+		WithTimeUTC(true),
+		WithColor(false),
+		WithOverflowPolicy(OVERFLOW_DROP),
+		WithCallerSkip(2),
+		WithBufferSize(16),
+	)
+	defer l.Close()
+	checks := []struct {
+		name string
+		ok   bool
+	}{
+		{"level", l.Level == INFO},
+		{"flags", l.Flags == FLAG_TIME|FLAG_LEVEL},
+		{"output type", l.OutputType == ONLY_TERMINAL},
+		{"terminal", l.terminal == &out && l.errTerminal == &errOut},
+		{"error to stderr", !l.errorToStderr},
+		{"file", l.filePath == dir+string(filepath.Separator) && l.fileName == "app.log"},
+		{"format", l.format == FORMAT_JSON},
+		{"time", l.timeFormat == time.RFC3339 && l.timeUTC},
+		{"color", !l.color && !l.errColor && l.colorForced},
+		{"overflow", l.overflow == OVERFLOW_DROP},
+		{"caller skip", l.callerSkip == 2},
+		{"buffer size", cap(l.msg) == 16},
+	}
+	for _, c := range checks {
+		if !c.ok {
+			t.Errorf("option %s not applied", c.name)
+		}
+	}
+	// 超出范围的等级被忽略
+	invalid := New(WithLevel(FATAL+1), WithOutputType(ONLY_TERMINAL))
+	defer invalid.Close()
+	if invalid.Level != DEBUG {
+		t.Error("invalid WithLevel applied")
+	}
+}
+
+func TestWithBufferSize(t *testing.T) {
+	w := newGateWriter()
+	l, _ := newTestLogger(t, WithOutput(w), WithBufferSize(5), WithOverflowPolicy(OVERFLOW_DROP), WithFlags(FLAG_NONE))
+	if got := cap(l.msg); got != 5 {
+		t.Fatalf("capacity %d, want 5", got)
+	}
+	l.Info(0)
+	<-w.entered
+	// 输出协程阻塞在第一条上，恰好填满缓冲的突发不应丢弃
+	for i := 1; i <= 5; i++ {
+		l.Info(i)
+	}
+	if got := l.DroppedCount(); got != 0 {
+		t.Fatalf("dropped %d while burst fits", got)
+	}
+	l.Info(6)
+	if got := l.DroppedCount(); got != 1 {
+		t.Fatalf("dropped %d, want 1", got)
+	}
+	close(w.release)
+	l.Flush()
+	if got := w.buf.String(); got != "0\n1\n2\n3\n4\n5\n" {
+		t.Fatalf("got %q", got)
+	}
+}
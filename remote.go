@@ -0,0 +1,130 @@
+package MyLog
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	remoteBufferSize   = 1000            // 待发送行的缓冲数量，已满时丢弃新的日志
+	remoteDialTimeout  = 3 * time.Second // 连接超时
+	remoteWriteTimeout = 5 * time.Second // 单次发送超时
+	remoteRetryDelay   = time.Second     // 连接失败后的重试间隔
+	remoteCloseTimeout = 3 * time.Second // 关闭时等待剩余日志发送的最长时间
+)
+
+// 远程日志服务输出目标，在独立协程中发送，断开后自动重连
+type remoteSink struct {
+	network string
+	addr    string
+	lines   chan []byte
+	quit    chan struct{}
+	done    chan struct{}
+	dropped *uint64     // 缓冲已满时累加丢弃数
+	report  func(error) // 上报发送错误
+}
+
+// 连接远程日志服务，之后每行日志在原有输出之外同时发送到该地址，network为"tcp"或"udp"
+// 发送在后台进行，连接断开后自动重连，期间最多缓存1000行，超出的日志被丢弃并计入DroppedCount
+// 输出由子对象与父对象共享，在子对象上调用时设置到父对象；日志对象已关闭时返回错误
+func (l *Logger) SetRemote(network, addr string) error {
+	l = l.backend()
+	conn, err := net.DialTimeout(network, addr, remoteDialTimeout)
+	if err != nil {
+		return err
+	}
+	s := &remoteSink{
+		network: network,
+		addr:    addr,
+		lines:   make(chan []byte, remoteBufferSize),
+		quit:    make(chan struct{}),
+		done:    make(chan struct{}),
+		dropped: &l.dropped,
+		report:  l.reportRemoteError,
+	}
+	go s.run(conn)
+
+	l.mu.Lock()
+	// 输出协程关闭时持锁取出发送目标，持锁判断可保证设置的目标一定会被关闭
+	if l.isClosed() {
+		l.mu.Unlock()
+		s.Close()
+		return errors.New("logger is closed")
+	}
+	old := l.remote
+	l.remote = s
+	l.mu.Unlock()
+	if old != nil {
+		old.Close()
+	}
+	return nil
+}
+
+// 同Logger.SetRemote，作用于默认日志对象
+func SetRemote(network, addr string) error {
+	return logger.SetRemote(network, addr)
+}
+
+// 在发送协程中上报错误
+func (l *Logger) reportRemoteError(err error) {
+	l.reportError(fmt.Errorf("write remote failed: %w", err))
+}
+
+// 放入发送缓冲，不阻塞调用方
+func (s *remoteSink) write(line []byte) {
+	buf := make([]byte, len(line))
+	copy(buf, line)
+	select {
+	case s.lines <- buf:
+	default:
+		atomic.AddUint64(s.dropped, 1)
+	}
+}
+
+// 逐行发送，失败时断开并重连后重发该行，直到发送成功或关闭
+func (s *remoteSink) run(conn net.Conn) {
+	defer close(s.done)
+	defer func() {
+		if conn != nil {
+			conn.Close()
+		}
+	}()
+	for line := range s.lines {
+		for {
+			if conn == nil {
+				var err error
+				if conn, err = net.DialTimeout(s.network, s.addr, remoteDialTimeout); err != nil {
+					select {
+					case <-time.After(remoteRetryDelay):
+						continue
+					case <-s.quit:
+						return
+					}
+				}
+			}
+			conn.SetWriteDeadline(time.Now().Add(remoteWriteTimeout))
+			if _, err := conn.Write(line); err != nil {
+				s.report(err)
+				conn.Close()
+				conn = nil
+				continue
+			}
+			break
+		}
+	}
+}
+
+// 停止发送，最多等待一段时间将缓冲中的日志发送完毕
+func (s *remoteSink) Close() error {
+	close(s.lines)
+	select {
+	case <-s.done:
+	case <-time.After(remoteCloseTimeout):
+		close(s.quit)
+		<-s.done
+	}
+	return nil
+}
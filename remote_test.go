@@ -0,0 +1,235 @@
+package MyLog
+
+import (
+	"bufio"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestRemoteTCP(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	got := make(chan string, 10)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		sc := bufio.NewScanner(conn)
+		for sc.Scan() {
+			got <- sc.Text()
+		}
+	}()
+
+	l, _ := newTestLogger(t)
+	if err := l.SetRemote("tcp", ln.Addr().String()); err != nil {
+		t.Fatal(err)
+	}
+	l.Info("one")
+	l.Warning("two")
+	l.Error("three")
+	// 关闭时等待缓冲中的日志发送完毕
+	l.Close()
+	for _, want := range []string{"[INFO   ] one", "[WARNING] two", "[ERROR  ] three"} {
+		select {
+		case line := <-got:
+			if line != want {
+				t.Fatalf("got %q, want %q", line, want)
+			}
+		case <-time.After(3 * time.Second):
+			t.Fatalf("timed out waiting for %q", want)
+		}
+	}
+}
+
+func TestRemoteUDP(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pc.Close()
+	l, _ := newTestLogger(t)
+	if err := l.SetRemote("udp", pc.LocalAddr().String()); err != nil {
+		t.Fatal(err)
+	}
+	l.Info("datagram")
+	l.Flush()
+	// UDP每行为一个数据报
+	pc.SetReadDeadline(time.Now().Add(3 * time.Second))
+	buf := make([]byte, 1024)
+	n, _, err := pc.ReadFrom(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(buf[:n]); got != "[INFO   ] datagram\n" {
+		t.Fatalf("got %q", got)
+	}
+}
+
+func TestRemoteDialFailure(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+	l, _ := newTestLogger(t)
+	if err := l.SetRemote("tcp", addr); err == nil {
+		t.Fatal("SetRemote to a closed port returned nil error")
+	}
+}
+
+// 不断接受连接并按行读取的远程日志服务，返回监听地址和收到的行
+func remoteCollector(t *testing.T) (net.Listener, chan string) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	got := make(chan string, 100)
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				sc := bufio.NewScanner(conn)
+				for sc.Scan() {
+					got <- sc.Text()
+				}
+			}()
+		}
+	}()
+	return ln, got
+}
+
+// 等待收到指定的行
+func expectLine(t *testing.T, got chan string, want string) {
+	t.Helper()
+	select {
+	case line := <-got:
+		if line != want {
+			t.Fatalf("got %q, want %q", line, want)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatalf("timed out waiting for %q", want)
+	}
+}
+
+func TestRemoteOnChildLogger(t *testing.T) {
+	ln, got := remoteCollector(t)
+	l, _ := newTestLogger(t, WithFlags(FLAG_NONE))
+	if err := l.Named("child").SetRemote("tcp", ln.Addr().String()); err != nil {
+		t.Fatal(err)
+	}
+	// 设置到父对象，父对象的日志同样发送，关闭父对象时发送剩余日志
+	l.Info("from root")
+	l.Close()
+	expectLine(t, got, "from root")
+	if err := l.SetRemote("tcp", ln.Addr().String()); err == nil {
+		t.Fatal("SetRemote on a closed logger returned nil error")
+	}
+}
+
+// 读取一行，超时失败
+func readRemoteLine(t *testing.T, sc *bufio.Scanner, conn net.Conn) string {
+	t.Helper()
+	conn.SetReadDeadline(time.Now().Add(3 * time.Second))
+	if !sc.Scan() {
+		t.Fatalf("read remote line: %v", sc.Err())
+	}
+	return sc.Text()
+}
+
+func TestRemoteReconnects(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	conns := make(chan net.Conn, 2)
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			conns <- conn
+		}
+	}()
+
+	l, _ := newTestLogger(t, WithFlags(FLAG_NONE))
+	sink := &errorSink{}
+	l.OnError(sink.report)
+	if err := l.SetRemote("tcp", ln.Addr().String()); err != nil {
+		t.Fatal(err)
+	}
+	first := <-conns
+	l.Info("before")
+	if got := readRemoteLine(t, bufio.NewScanner(first), first); got != "before" {
+		t.Fatalf("got %q", got)
+	}
+	// 服务端断开连接，之后的日志在新的连接上到达，断开时写入的行可能丢失
+	first.Close()
+	deadline := time.After(5 * time.Second)
+	for i := 0; ; i++ {
+		l.Info("after")
+		select {
+		case second := <-conns:
+			defer second.Close()
+			if got := readRemoteLine(t, bufio.NewScanner(second), second); got != "after" {
+				t.Fatalf("got %q on the new connection", got)
+			}
+			if len(sink.Errors()) == 0 {
+				t.Fatal("broken connection not reported")
+			}
+			return
+		case <-time.After(50 * time.Millisecond):
+		case <-deadline:
+			t.Fatalf("no reconnect after %d lines", i+1)
+		}
+	}
+}
+
+func TestRemoteDownDoesNotBlock(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	accepted := make(chan net.Conn, 1)
+	go func() {
+		if conn, err := ln.Accept(); err == nil {
+			accepted <- conn
+		}
+	}()
+	l, _ := newTestLogger(t, WithFlags(FLAG_NONE))
+	l.OnError((&errorSink{}).report)
+	if err := l.SetRemote("tcp", ln.Addr().String()); err != nil {
+		t.Fatal(err)
+	}
+	// 停止服务，重连持续失败
+	ln.Close()
+	(<-accepted).Close()
+
+	const n = remoteBufferSize + 500
+	start := time.Now()
+	for i := 0; i < n; i++ {
+		l.Info(i)
+	}
+	l.Flush()
+	if d := time.Since(start); d > time.Second {
+		t.Fatalf("logging with the collector down took %v", d)
+	}
+	// 缓冲最多1000行，发送协程最多取走断开前的几行，其余丢弃
+	if got := l.DroppedCount(); got < n-remoteBufferSize-10 {
+		t.Fatalf("dropped %d, want at least %d", got, n-remoteBufferSize-10)
+	}
+}
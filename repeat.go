@@ -0,0 +1,101 @@
+package MyLog
+
+import (
+	"fmt"
+)
+
+// 设置采样：连续相同的消息每n条只输出一条，并注明期间被省略的条数，n小于等于1时不采样
+// 重复中断、刷新或关闭时，尚未汇总的省略条数以最后一条被省略的消息加"(repeated K times)"输出
+func (l *Logger) SetSampling(n int) {
+	root := l.backend()
+	root.inWriter(func() {
+		root.flushRepeat()
+		root.sampling = n
+		root.sampleMsg = ""
+		root.sampleCount = 0
+	})
+}
+
+// 同Logger.SetSampling，作用于默认日志对象
+func SetSampling(n int) {
+	logger.SetSampling(n)
+}
+
+// 按采样设置判断是否输出该条日志，不同的消息到来时先输出之前尚未汇总的省略条数，仅在输出协程中调用
+func (l *Logger) sample(log *logMsg) bool {
+	if l.sampleCount == 0 || log.level != l.sampleLevel || log.msg != l.sampleMsg {
+		l.flushRepeat()
+		l.sampleLevel, l.sampleMsg, l.sampleCount = log.level, log.msg, 1
+		return true
+	}
+	l.sampleCount++
+	if (l.sampleCount-1)%l.sampling != 0 {
+		l.sampleSkipped++
+		// log输出后会被回收，保存副本
+		last := *log
+		l.sampleLast = &last
+		return false
+	}
+	log.msg += fmt.Sprintf(" (repeated %d times)", l.sampleSkipped)
+	l.sampleSkipped = 0
+	l.sampleLast = nil
+	return true
+}
+
+// 输出采样中尚未汇总的省略条数
+func (l *Logger) flushSample() {
+	if l.sampleSkipped == 0 {
+		return
+	}
+	summary := *l.sampleLast
+	summary.msg += fmt.Sprintf(" (repeated %d times)", l.sampleSkipped)
+	summary.fields = nil
+	l.sampleSkipped = 0
+	l.sampleLast = nil
+	l.writeLine(&summary)
+}
+
+// 设置是否合并连续重复的消息，重复结束或刷新时输出一条"last message repeated N times"
+// 之前尚未输出的重复汇总在输出协程中先行输出
+func (l *Logger) SetDedup(enable bool) {
+	root := l.backend()
+	root.inWriter(func() {
+		root.flushRepeat()
+		root.dedup = enable
+		root.dedupMsg = nil
+	})
+}
+
+// 同Logger.SetDedup，作用于默认日志对象
+func SetDedup(enable bool) {
+	logger.SetDedup(enable)
+}
+
+// 判断是否与上一条消息重复，重复时只计数返回true，否则先输出之前的重复汇总，仅在输出协程中调用
+func (l *Logger) dedupe(log *logMsg) bool {
+	if l.dedupMsg != nil && log.level == l.dedupMsg.level && log.msg == l.dedupMsg.msg {
+		l.dedupCount++
+		// log输出后会被回收，保存副本
+		last := *log
+		l.dedupLast = &last
+		return true
+	}
+	l.flushRepeat()
+	msg := *log
+	l.dedupMsg = &msg
+	return false
+}
+
+// 输出尚未汇总的重复次数，之后输出采样省略的条数
+func (l *Logger) flushRepeat() {
+	defer l.flushSample()
+	if l.dedupCount == 0 {
+		return
+	}
+	summary := *l.dedupLast
+	summary.msg = fmt.Sprintf("last message repeated %d times", l.dedupCount)
+	summary.fields = nil
+	l.dedupCount = 0
+	l.dedupLast = nil
+	l.writeLine(&summary)
+}
@@ -0,0 +1,95 @@
+package MyLog
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSamplingBurst(t *testing.T) {
+	l, buf := newTestLogger(t)
+	l.SetSampling(5)
+	for i := 0; i < 12; i++ {
+		l.Error("disk full")
+	}
+	l.Info("other")
+	l.Flush()
+	want := []string{
+		"[ERROR  ] disk full",
+		"[ERROR  ] disk full (repeated 4 times)",
+		"[ERROR  ] disk full (repeated 4 times)",
+		"[ERROR  ] disk full (repeated 1 times)",
+		"[INFO   ] other",
+	}
+	if got := lines(buf); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("got %q", got)
+	}
+}
+
+// 尚未汇总的省略条数在刷新和关闭时输出
+func TestSamplingTailOnFlushAndClose(t *testing.T) {
+	l, buf := newTestLogger(t)
+	l.SetSampling(5)
+	for i := 0; i < 8; i++ {
+		l.Warning("slow")
+	}
+	l.Flush()
+	want := []string{
+		"[WARNING] slow",
+		"[WARNING] slow (repeated 4 times)",
+		"[WARNING] slow (repeated 2 times)",
+	}
+	if got := lines(buf); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("after Flush got %q", got)
+	}
+
+	// 刷新不中断重复，第9、10条仍被省略，关闭时汇总
+	buf.Reset()
+	for i := 0; i < 2; i++ {
+		l.Warning("slow")
+	}
+	l.Close()
+	if got := lines(buf); len(got) != 1 || got[0] != "[WARNING] slow (repeated 2 times)" {
+		t.Fatalf("after Close got %q", got)
+	}
+}
+
+func TestDedupSummary(t *testing.T) {
+	l, buf := newTestLogger(t)
+	l.SetDedup(true)
+	for i := 0; i < 5; i++ {
+		l.Info("same")
+	}
+	l.Info("different")
+	l.Info("tail")
+	l.Info("tail")
+	l.Flush()
+	want := []string{
+		"[INFO   ] same",
+		"[INFO   ] last message repeated 4 times",
+		"[INFO   ] different",
+		"[INFO   ] tail",
+		"[INFO   ] last message repeated 1 times",
+	}
+	if got := lines(buf); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("got %q", got)
+	}
+}
+
+// 关闭合并时的重复汇总在输出协程中写入文件，不与定时刷新竞争
+func TestSetDedupFlushesOnWriter(t *testing.T) {
+	l, path := newFileLogger(t, WithFlags(FLAG_NONE))
+	l.SetFlushInterval(10 * time.Millisecond)
+	l.SetDedup(true)
+	for i := 0; i < 4; i++ {
+		l.Info("dup")
+	}
+	// 等待输出协程处理完并触发定时刷新，重复计数尚未汇总
+	time.Sleep(50 * time.Millisecond)
+	l.SetDedup(false)
+	l.Info("dup")
+	l.Flush()
+	if got := readFile(t, path); got != "dup\nlast message repeated 3 times\ndup\n" {
+		t.Fatalf("file = %q", got)
+	}
+}
@@ -0,0 +1,94 @@
+package MyLog
+
+import (
+	"io"
+	"net"
+	"sync"
+)
+
+// 保存最近若干行日志的环形缓冲
+type ringBuffer struct {
+	mu    sync.Mutex
+	lines [][]byte // 日志行（含换行）
+	next  int      // 下一行写入的位置
+	full  bool     // 是否已写满一圈
+}
+
+// 创建容量为n行的环形缓冲
+func newRingBuffer(n int) *ringBuffer {
+	return &ringBuffer{lines: make([][]byte, n)}
+}
+
+// 写入一行，缓冲已满时覆盖最旧的一行
+func (r *ringBuffer) add(line []byte) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	// line为复用的缓冲，保存副本
+	r.lines[r.next] = append([]byte(nil), line...)
+	r.next++
+	if r.next == len(r.lines) {
+		r.next = 0
+		r.full = true
+	}
+}
+
+// 按从旧到新的顺序获取缓冲中的所有行
+func (r *ringBuffer) snapshot() [][]byte {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.full {
+		return append([][]byte(nil), r.lines[:r.next]...)
+	}
+	lines := make([][]byte, 0, len(r.lines))
+	lines = append(lines, r.lines[r.next:]...)
+	return append(lines, r.lines[:r.next]...)
+}
+
+// 设置在内存中保留最近n行日志，不受输出类型影响，可用于崩溃时导出，n小于等于0时关闭
+func (l *Logger) SetRingBuffer(n int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if n <= 0 {
+		l.ring = nil
+		return
+	}
+	l.ring = newRingBuffer(n)
+}
+
+// 同Logger.SetRingBuffer，作用于默认日志对象
+func SetRingBuffer(n int) {
+	logger.SetRingBuffer(n)
+}
+
+// 按从旧到新的顺序将内存中保留的日志写入w，未开启时不写入任何内容
+func (l *Logger) DumpRingBuffer(w io.Writer) error {
+	_, err := l.RingBuffer().WriteTo(w)
+	return err
+}
+
+// 获取内存中保留的最近日志，可直接写入http.ResponseWriter等目标而不拼接成字符串，未开启时写入0字节
+func (l *Logger) RingBuffer() io.WriterTo {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.ring
+}
+
+// 同Logger.RingBuffer，作用于默认日志对象
+func RingBuffer() io.WriterTo {
+	return logger.RingBuffer()
+}
+
+// 实现io.WriterTo，按从旧到新的顺序写入w，写入的是调用时的快照
+func (r *ringBuffer) WriteTo(w io.Writer) (int64, error) {
+	if r == nil {
+		return 0, nil
+	}
+	// 目标支持批量写入（如网络连接）时一次写入所有行
+	bufs := net.Buffers(r.snapshot())
+	return bufs.WriteTo(w)
+}
+
+// 同Logger.DumpRingBuffer，作用于默认日志对象
+func DumpRingBuffer(w io.Writer) error {
+	return logger.DumpRingBuffer(w)
+}
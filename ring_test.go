@@ -0,0 +1,76 @@
+package MyLog
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRingBufferKeepsLastLines(t *testing.T) {
+	l, _ := newTestLogger(t, WithOutputType(ONLY_FILE), WithFile(t.TempDir()+"/ring.log"), WithFlags(FLAG_NONE))
+	l.SetRingBuffer(3)
+	// 低于文件等级的日志也记录在环形缓冲中
+	l.SetFileLevel(ERROR)
+	for i := 1; i <= 5; i++ {
+		l.Info(i)
+	}
+	l.Flush()
+	var buf bytes.Buffer
+	if err := l.DumpRingBuffer(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if got := buf.String(); got != "3\n4\n5\n" {
+		t.Fatalf("got %q", got)
+	}
+
+	// 未写满一圈时按写入顺序输出
+	l.SetRingBuffer(4)
+	l.Info("a")
+	l.Info("b")
+	l.Flush()
+	buf.Reset()
+	l.RingBuffer().WriteTo(&buf)
+	if got := buf.String(); got != "a\nb\n" {
+		t.Fatalf("got %q", got)
+	}
+
+	l.SetRingBuffer(0)
+	buf.Reset()
+	if err := l.DumpRingBuffer(&buf); err != nil || buf.Len() != 0 {
+		t.Fatalf("disabled ring wrote %q, %v", buf.String(), err)
+	}
+}
+
+func TestRingBufferWriterToHTTP(t *testing.T) {
+	l, _ := newTestLogger(t)
+	l.SetRingBuffer(10)
+	l.Info("first")
+	l.Error("second")
+	l.Flush()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// 直接写入响应，不拼接成字符串
+		if _, err := l.RingBuffer().WriteTo(w); err != nil {
+			t.Error(err)
+		}
+	}))
+	defer srv.Close()
+	resp, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(body); got != "[INFO   ] first\n[ERROR  ] second\n" {
+		t.Fatalf("got %q", got)
+	}
+	// WriteTo返回写入的字节数
+	var buf bytes.Buffer
+	if n, err := l.RingBuffer().WriteTo(&buf); err != nil || n != int64(buf.Len()) {
+		t.Fatalf("WriteTo = %d, %v; wrote %d", n, err, buf.Len())
+	}
+}
@@ -0,0 +1,34 @@
+//go:build !windows && !plan9
+
+package MyLog
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// 收到SIGHUP时重新打开日志文件，配合logrotate的postrotate脚本使用，日志对象关闭后停止处理
+func (l *Logger) HandleSIGHUP() {
+	l = l.backend()
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, syscall.SIGHUP)
+	go func() {
+		defer signal.Stop(c)
+		for {
+			select {
+			case <-c:
+				if err := l.ReopenFile(); err != nil {
+					l.reportError(err)
+				}
+			case <-l.done:
+				return
+			}
+		}
+	}()
+}
+
+// 同Logger.HandleSIGHUP，作用于默认日志对象
+func HandleSIGHUP() {
+	logger.HandleSIGHUP()
+}
@@ -0,0 +1,39 @@
+//go:build !windows && !plan9
+
+package MyLog
+
+import (
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestHandleSIGHUP(t *testing.T) {
+	l, path := newFileLogger(t, WithFlags(FLAG_NONE))
+	l.HandleSIGHUP()
+	l.Info("old")
+	l.Flush()
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := syscall.Kill(os.Getpid(), syscall.SIGHUP); err != nil {
+		t.Fatal(err)
+	}
+	// 信号异步处理，等待新文件被创建
+	deadline := time.Now().Add(3 * time.Second)
+	for {
+		if _, err := os.Stat(path); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("file not reopened after SIGHUP")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	l.Info("new")
+	l.Flush()
+	if got := readFile(t, path); got != "new\n" {
+		t.Fatalf("fresh file got %q", got)
+	}
+}
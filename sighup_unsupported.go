@@ -0,0 +1,11 @@
+//go:build windows || plan9
+
+package MyLog
+
+// 当前平台没有SIGHUP信号，不做任何处理，可直接调用ReopenFile
+func (l *Logger) HandleSIGHUP() {}
+
+// 同Logger.HandleSIGHUP，作用于默认日志对象
+func HandleSIGHUP() {
+	logger.HandleSIGHUP()
+}
@@ -0,0 +1,18 @@
+//go:build !windows && !plan9
+
+package MyLog
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// 向当前进程重新发送信号
+func raiseSignal(sig os.Signal) error {
+	s, ok := sig.(syscall.Signal)
+	if !ok {
+		return errors.New("unsupported signal")
+	}
+	return syscall.Kill(os.Getpid(), s)
+}
@@ -0,0 +1,78 @@
+//go:build !windows && !plan9
+
+package MyLog
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// 子进程中运行：写入日志后向自身发送SIGTERM，进程应在日志关闭后被信号结束
+func TestCloseOnSignalProcess(t *testing.T) {
+	path := os.Getenv("MYLOG_SIGNAL_LOG")
+	if path == "" {
+		t.Skip("only runs in the child process of TestCloseOnSignalTerminates")
+	}
+	l := New(WithOutputType(ONLY_FILE), WithFile(path), WithFlags(FLAG_NONE))
+	l.CloseOnSignal(syscall.SIGTERM)
+	l.Info("before signal")
+	syscall.Kill(os.Getpid(), syscall.SIGTERM)
+	time.Sleep(5 * time.Second)
+	t.Fatal("process still running after SIGTERM")
+}
+
+func TestCloseOnSignalTerminates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "signal.log")
+	cmd := exec.Command(os.Args[0], "-test.run=^TestCloseOnSignalProcess$")
+	cmd.Env = append(os.Environ(), "MYLOG_SIGNAL_LOG="+path)
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		t.Fatalf("child exited with %v, want killed by SIGTERM", err)
+	}
+	status, ok := exitErr.Sys().(syscall.WaitStatus)
+	if !ok || !status.Signaled() || status.Signal() != syscall.SIGTERM {
+		t.Fatalf("child exited with %v, want killed by SIGTERM", err)
+	}
+	if got := readFile(t, path); got != "before signal\n" {
+		t.Fatalf("log file got %q", got)
+	}
+}
+
+// 程序自己也捕获该信号时，重新发送的信号交由程序处理，进程不会退出
+func TestCloseOnSignalReraises(t *testing.T) {
+	l, path := newFileLogger(t, WithFlags(FLAG_NONE))
+	c := make(chan os.Signal, 2)
+	signal.Notify(c, syscall.SIGUSR1)
+	defer signal.Stop(c)
+	l.CloseOnSignal(syscall.SIGUSR1)
+	l.Info("before signal")
+	if err := syscall.Kill(os.Getpid(), syscall.SIGUSR1); err != nil {
+		t.Fatal(err)
+	}
+	// 一次来自原始信号，一次来自关闭后重新发送的信号
+	for i := 0; i < 2; i++ {
+		select {
+		case <-c:
+		case <-time.After(3 * time.Second):
+			t.Fatalf("received %d signals, want 2", i)
+		}
+	}
+	if got := readFile(t, path); got != "before signal\n" {
+		t.Fatalf("log file got %q", got)
+	}
+	// 已被信号关闭，再次关闭不会阻塞
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+	l.Info("after close")
+	if got := readFile(t, path); got != "before signal\n" {
+		t.Fatalf("logged after close: %q", got)
+	}
+}
@@ -0,0 +1,13 @@
+//go:build windows || plan9
+
+package MyLog
+
+import (
+	"errors"
+	"os"
+)
+
+// 当前平台不支持向自身发送信号，由调用方退出进程
+func raiseSignal(sig os.Signal) error {
+	return errors.New("raise signal not supported")
+}
@@ -0,0 +1,119 @@
+package MyLog
+
+import (
+	"fmt"
+	"io"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	sinkBufferSize   = 1000            // 未指定时独立输出目标的缓冲行数
+	sinkCloseTimeout = 3 * time.Second // 关闭时等待剩余日志写入的最长时间
+)
+
+// 拥有独立协程和缓冲的输出目标，写入慢的目标只会使自身的缓冲积压，不影响其他目标
+type asyncSink struct {
+	w       io.Writer
+	policy  OverflowPolicy // 缓冲已满时的丢弃策略
+	lines   chan []byte
+	quit    chan struct{}
+	done    chan struct{}
+	dropped *uint64     // 缓冲已满时累加丢弃数
+	report  func(error) // 上报写入错误
+}
+
+// 添加在独立协程中写入的输出目标，每个目标有各自的缓冲，适用于网络连接等可能写入缓慢的目标
+// bufferSize为缓冲的行数，小于等于0时为1000；缓冲已满时按policy丢弃并计入DroppedCount
+// policy为OVERFLOW_BLOCK时按OVERFLOW_DROP处理，避免慢目标阻塞输出协程
+// 通过RemoveWriter传入同一个w移除，Close时最多等待3秒将缓冲中的日志写完；日志对象已关闭时不做任何处理
+// 输出目标由子对象与父对象共享，在子对象上调用时添加到父对象
+func (l *Logger) AddAsyncWriter(w io.Writer, bufferSize int, policy OverflowPolicy) {
+	if bufferSize <= 0 {
+		bufferSize = sinkBufferSize
+	}
+	if policy == OVERFLOW_BLOCK {
+		policy = OVERFLOW_DROP
+	}
+	l = l.backend()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	// 输出协程关闭时持锁取出所有目标，持锁判断可保证添加的目标一定会被关闭，不会遗留写入协程
+	if l.isClosed() {
+		return
+	}
+	s := &asyncSink{
+		w:       w,
+		policy:  policy,
+		lines:   make(chan []byte, bufferSize),
+		quit:    make(chan struct{}),
+		done:    make(chan struct{}),
+		dropped: &l.dropped,
+		report:  l.reportSinkError,
+	}
+	go s.run()
+	// 复制一份新切片，避免与输出协程读取的切片冲突
+	sinks := make([]*asyncSink, 0, len(l.sinks)+1)
+	sinks = append(sinks, l.sinks...)
+	l.sinks = append(sinks, s)
+}
+
+// 同Logger.AddAsyncWriter，作用于默认日志对象
+func AddAsyncWriter(w io.Writer, bufferSize int, policy OverflowPolicy) {
+	logger.AddAsyncWriter(w, bufferSize, policy)
+}
+
+// 在写入协程中上报错误，reportError不获取mu，关闭目标时持有锁也不会死锁
+func (l *Logger) reportSinkError(err error) {
+	l.reportError(fmt.Errorf("write async writer failed: %w", err))
+}
+
+// 放入写入缓冲，不阻塞调用方，只在输出协程中调用
+func (s *asyncSink) write(line []byte) {
+	buf := make([]byte, len(line))
+	copy(buf, line)
+	for {
+		select {
+		case s.lines <- buf:
+			return
+		default:
+		}
+		if s.policy != OVERFLOW_DROP_OLDEST {
+			atomic.AddUint64(s.dropped, 1)
+			return
+		}
+		// 缓冲已满，取出最旧的一行后重试
+		select {
+		case <-s.lines:
+			atomic.AddUint64(s.dropped, 1)
+		default:
+		}
+	}
+}
+
+// 逐行写入，直到缓冲关闭或超时退出
+func (s *asyncSink) run() {
+	defer close(s.done)
+	for line := range s.lines {
+		select {
+		case <-s.quit:
+			return
+		default:
+		}
+		if _, err := s.w.Write(line); err != nil {
+			s.report(err)
+		}
+	}
+}
+
+// 停止写入，最多等待一段时间将缓冲中的日志写完，可能阻塞数秒，调用方不应持有日志对象的锁
+func (s *asyncSink) Close() error {
+	close(s.lines)
+	select {
+	case <-s.done:
+	case <-time.After(sinkCloseTimeout):
+		// 正在进行的写入无法中断，不再等待写入协程退出
+		close(s.quit)
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package MyLog
+
+import (
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// 第一行写入慢目标后阻塞，返回日志对象和慢目标
+func slowSinkLogger(t *testing.T, policy OverflowPolicy) (*Logger, *syncBuffer, *gateWriter) {
+	t.Helper()
+	fast := &syncBuffer{}
+	l, _ := newTestLogger(t, WithOutput(fast), WithFlags(FLAG_NONE))
+	slow := newGateWriter()
+	l.AddAsyncWriter(slow, 2, policy)
+	l.Info(1)
+	<-slow.entered
+	return l, fast, slow
+}
+
+func TestSlowSinkDoesNotBlockOthers(t *testing.T) {
+	l, fast, slow := slowSinkLogger(t, OVERFLOW_DROP)
+	start := time.Now()
+	for i := 2; i <= 10; i++ {
+		l.Info(i)
+	}
+	l.Flush()
+	if d := time.Since(start); d > time.Second {
+		t.Fatalf("slow sink delayed the other outputs for %v", d)
+	}
+	if n := strings.Count(fast.String(), "\n"); n != 10 {
+		t.Fatalf("fast output got %d lines, want 10", n)
+	}
+	// 正在写入1行，缓冲2行，其余丢弃
+	if got := l.DroppedCount(); got != 7 {
+		t.Fatalf("dropped %d, want 7", got)
+	}
+	close(slow.release)
+	l.RemoveWriter(slow)
+	if got := slow.buf.String(); got != "1\n2\n3\n" {
+		t.Fatalf("slow sink got %q", got)
+	}
+}
+
+func TestSlowSinkDropOldest(t *testing.T) {
+	l, _, slow := slowSinkLogger(t, OVERFLOW_DROP_OLDEST)
+	for i := 2; i <= 10; i++ {
+		l.Info(i)
+	}
+	l.Flush()
+	if got := l.DroppedCount(); got != 7 {
+		t.Fatalf("dropped %d, want 7", got)
+	}
+	close(slow.release)
+	l.RemoveWriter(slow)
+	if got := slow.buf.String(); got != "1\n9\n10\n" {
+		t.Fatalf("slow sink got %q", got)
+	}
+}
+
+func TestAsyncWriterDrainedOnClose(t *testing.T) {
+	l, _ := newTestLogger(t, WithFlags(FLAG_NONE))
+	sink := &syncBuffer{}
+	// 在子对象上添加的目标同样由父对象关闭
+	l.Named("child").AddAsyncWriter(sink, 0, OVERFLOW_BLOCK)
+	for i := 0; i < 100; i++ {
+		l.Info(i)
+	}
+	l.Close()
+	got := strings.Split(strings.TrimSuffix(sink.String(), "\n"), "\n")
+	if len(got) != 100 || got[99] != strconv.Itoa(99) {
+		t.Fatalf("sink got %d lines after Close", len(got))
+	}
+}
+
+func TestAsyncWriterAfterClose(t *testing.T) {
+	l, _ := newTestLogger(t)
+	l.Close()
+	before := runtime.NumGoroutine()
+	l.AddAsyncWriter(&syncBuffer{}, 0, OVERFLOW_DROP)
+	if n := runtime.NumGoroutine(); n > before {
+		t.Fatalf("goroutines %d > %d after AddAsyncWriter on a closed logger", n, before)
+	}
+}
+
+func TestAsyncWriterReportsErrors(t *testing.T) {
+	sink := &errorSink{}
+	l, _ := newTestLogger(t)
+	l.OnError(sink.report)
+	l.AddAsyncWriter(failWriter{}, 0, OVERFLOW_DROP)
+	l.Info("x")
+	l.Close()
+	if errs := sink.Errors(); len(errs) != 1 || !strings.Contains(errs[0].Error(), "write async writer failed") {
+		t.Fatalf("errors = %v", errs)
+	}
+}
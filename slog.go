@@ -0,0 +1,104 @@
+package MyLog
+
+import (
+	"context"
+	"log/slog"
+)
+
+// 将slog的记录转交给MyLog输出的slog.Handler
+type slogHandler struct {
+	logger *Logger
+	attrs  []field
+	group  string
+}
+
+// 创建基于当前日志对象的slog.Handler，可通过slog.New使用
+func (l *Logger) NewSlogHandler() slog.Handler {
+	return &slogHandler{logger: l}
+}
+
+// 同Logger.NewSlogHandler，作用于默认日志对象
+func NewSlogHandler() slog.Handler {
+	return logger.NewSlogHandler()
+}
+
+// 将slog的日志等级映射为MyLog的日志等级
+func slogLevel(level slog.Level) LevelLog {
+	switch {
+	case level < slog.LevelDebug:
+		return TRACE
+	case level < slog.LevelInfo:
+		return DEBUG
+	case level < slog.LevelWarn:
+		return INFO
+	case level < slog.LevelError:
+		return WARNING
+	default:
+		return ERROR
+	}
+}
+
+// 判断该等级的日志是否会被输出
+func (h *slogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return h.logger.IsEnabled(slogLevel(level))
+}
+
+// 输出一条slog记录，属性转换为key=value字段
+func (h *slogHandler) Handle(ctx context.Context, r slog.Record) error {
+	fields := make([]field, 0, len(h.attrs)+r.NumAttrs())
+	fields = append(fields, h.attrs...)
+	r.Attrs(func(a slog.Attr) bool {
+		fields = appendAttr(fields, h.group, a)
+		return true
+	})
+	level := slogLevel(r.Level)
+	var msg interface{} = r.Message
+	if level >= ERROR {
+		// 属性中的error同样展开错误链
+		args := make([]interface{}, len(fields))
+		for i, f := range fields {
+			args[i] = f.value
+		}
+		msg = argsMsg{text: r.Message, args: args}
+	}
+	h.logger.submit(ctx, level, nil, msg, fields, r.PC)
+	return nil
+}
+
+// 返回附加了属性的新Handler
+func (h *slogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	fields := make([]field, 0, len(h.attrs)+len(attrs))
+	fields = append(fields, h.attrs...)
+	for _, a := range attrs {
+		fields = appendAttr(fields, h.group, a)
+	}
+	return &slogHandler{logger: h.logger, attrs: fields, group: h.group}
+}
+
+// 返回指定分组的新Handler，之后的属性key以"分组."为前缀
+func (h *slogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return &slogHandler{logger: h.logger, attrs: h.attrs, group: h.group + name + "."}
+}
+
+// 将slog属性展开为字段，分组属性的key以"分组."拼接
+func appendAttr(fields []field, prefix string, a slog.Attr) []field {
+	a.Value = a.Value.Resolve()
+	if a.Value.Kind() == slog.KindGroup {
+		// 空key的分组直接展开到当前层级
+		if a.Key != "" {
+			prefix += a.Key + "."
+		}
+		for _, ga := range a.Value.Group() {
+			fields = appendAttr(fields, prefix, ga)
+		}
+		return fields
+	}
+	// 空key的属性按slog的约定忽略
+	if a.Key == "" {
+		return fields
+	}
+	return append(fields, field{key: prefix + a.Key, value: a.Value.Any()})
+}
@@ -0,0 +1,46 @@
+package MyLog
+
+import (
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestSlogHandler(t *testing.T) {
+	l, buf := newTestLogger(t, WithFlags(FLAG_LEVEL|FLAG_FILENAME))
+	l.SetLevel(INFO)
+	log := slog.New(l.NewSlogHandler())
+	log.Debug("filtered")
+	log.Info("plain", "k", 1)
+	log.With("svc", "api").WithGroup("req").Warn("slow", "ms", 120, slog.Group("peer", "ip", "10.0.0.1"))
+	log.Error("failed", slog.Group("", "inline", true), "", "ignored")
+	l.Flush()
+	// 调用位置为slog的调用方，而非slog内部
+	want := []string{
+		"[INFO   ] [slog_test.go] k=1 plain",
+		"[WARNING] [slog_test.go] svc=api req.ms=120 req.peer.ip=10.0.0.1 slow",
+		"[ERROR  ] [slog_test.go] inline=true failed",
+	}
+	if got := lines(buf); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+func TestSlogLevelMapping(t *testing.T) {
+	tests := []struct {
+		level slog.Level
+		want  LevelLog
+	}{
+		{slog.LevelDebug - 4, TRACE},
+		{slog.LevelDebug, DEBUG},
+		{slog.LevelInfo, INFO},
+		{slog.LevelWarn, WARNING},
+		{slog.LevelError, ERROR},
+		{slog.LevelError + 4, ERROR},
+	}
+	for _, tt := range tests {
+		if got := slogLevel(tt.level); got != tt.want {
+			t.Errorf("slogLevel(%v) = %v, want %v", tt.level, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+//go:build !windows && !plan9
+
+package MyLog
+
+import (
+	"errors"
+	"log/syslog"
+)
+
+// 系统日志输出目标
+type syslogSink struct {
+	w *syslog.Writer
+}
+
+// 连接系统日志服务，之后的日志在原有输出之外同时发送到syslog
+// network和addr为空时连接本机syslog，tag为日志标识
+// 输出由子对象与父对象共享，在子对象上调用时设置到父对象；日志对象已关闭时返回错误
+func (l *Logger) SetSyslog(network, addr, tag string) error {
+	l = l.backend()
+	w, err := syslog.Dial(network, addr, syslog.LOG_INFO|syslog.LOG_USER, tag)
+	if err != nil {
+		return err
+	}
+	l.mu.Lock()
+	// 输出协程关闭时持锁关闭syslog，持锁判断可保证设置的连接一定会被关闭
+	if l.isClosed() {
+		l.mu.Unlock()
+		w.Close()
+		return errors.New("logger is closed")
+	}
+	old := l.syslog
+	l.syslog = &syslogSink{w: w}
+	l.mu.Unlock()
+	if old != nil {
+		old.Close()
+	}
+	return nil
+}
+
+// 同Logger.SetSyslog，作用于默认日志对象
+func SetSyslog(network, addr, tag string) error {
+	return logger.SetSyslog(network, addr, tag)
+}
+
+// 按日志等级映射为syslog优先级后发送
+func (s *syslogSink) writeLevel(level LevelLog, content string) error {
+	switch level {
+	case TRACE, DEBUG:
+		return s.w.Debug(content)
+	case INFO:
+		return s.w.Info(content)
+	case WARNING:
+		return s.w.Warning(content)
+	case ERROR:
+		return s.w.Err(content)
+	default:
+		return s.w.Crit(content)
+	}
+}
+
+// 关闭与syslog的连接
+func (s *syslogSink) Close() error {
+	return s.w.Close()
+}
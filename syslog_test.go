@@ -0,0 +1,51 @@
+//go:build !windows && !plan9
+
+package MyLog
+
+import (
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSyslogPriorities(t *testing.T) {
+	skipIfDebugStripped(t)
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	l, _ := newTestLogger(t, WithFlags(FLAG_NONE))
+	child := l.Named("child")
+	// 在子对象上设置同样作用于父对象
+	if err := child.SetSyslog("udp", conn.LocalAddr().String(), "mylog"); err != nil {
+		t.Fatal(err)
+	}
+	l.Debug("d")
+	l.Info("i")
+	l.Warning("w")
+	l.Error("e")
+	child.Info("c")
+	l.Flush()
+
+	// LOG_USER为1，优先级为1*8+严重程度
+	want := []string{"<15>", "<14>", "<12>", "<11>", "<14>"}
+	msgs := []string{"d", "i", "w", "e", "[child] c"}
+	buf := make([]byte, 1024)
+	for i := range want {
+		conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		packet := string(buf[:n])
+		if !strings.HasPrefix(packet, want[i]) || !strings.Contains(packet, "mylog") || !strings.HasSuffix(strings.TrimSuffix(packet, "\n"), ": "+msgs[i]) {
+			t.Fatalf("packet %d = %q", i, packet)
+		}
+	}
+	l.Close()
+	if err := l.SetSyslog("udp", conn.LocalAddr().String(), "mylog"); err == nil {
+		t.Fatal("SetSyslog on a closed logger returned nil error")
+	}
+}
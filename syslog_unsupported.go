@@ -0,0 +1,17 @@
+//go:build windows || plan9
+
+package MyLog
+
+import (
+	"errors"
+)
+
+// 当前平台不支持syslog，始终返回错误
+func (l *Logger) SetSyslog(network, addr, tag string) error {
+	return errors.New("syslog is not supported on this platform")
+}
+
+// 同Logger.SetSyslog，作用于默认日志对象
+func SetSyslog(network, addr, tag string) error {
+	return logger.SetSyslog(network, addr, tag)
+}
@@ -0,0 +1,127 @@
+package MyLog
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// 模板中可用的占位符，及其需要采集的输出字段
+var templateKeys = map[string]LogFlag{
+	"time":      FLAG_TIME,
+	"level":     FLAG_LEVEL,
+	"name":      FLAG_NONE,
+	"goroutine": FLAG_THREADID,
+	"file":      FLAG_FILENAME,
+	"func":      FLAG_FUNCNAME,
+	"line":      FLAG_LINENO,
+	"fields":    FLAG_NONE,
+	"msg":       FLAG_NONE,
+}
+
+// 模板中的一段，key为空时表示原样输出的文本
+type templatePart struct {
+	text string
+	key  string
+}
+
+// 解析后的日志行模板
+type lineTemplate struct {
+	parts []templatePart
+	flags LogFlag // 模板用到的输出字段，提交消息时据此采集调用信息
+}
+
+// 解析模板，占位符形如{time}，未知或未闭合的占位符返回错误
+func parseTemplate(tmpl string) (*lineTemplate, error) {
+	t := &lineTemplate{}
+	for len(tmpl) > 0 {
+		start := strings.IndexByte(tmpl, '{')
+		if start < 0 {
+			t.parts = append(t.parts, templatePart{text: tmpl})
+			break
+		}
+		if start > 0 {
+			t.parts = append(t.parts, templatePart{text: tmpl[:start]})
+		}
+		end := strings.IndexByte(tmpl[start:], '}')
+		if end < 0 {
+			return nil, fmt.Errorf("unclosed placeholder in template: %q", tmpl[start:])
+		}
+		key := tmpl[start+1 : start+end]
+		flag, ok := templateKeys[key]
+		if !ok {
+			return nil, fmt.Errorf("unknown placeholder {%s} in template", key)
+		}
+		t.parts = append(t.parts, templatePart{key: key})
+		t.flags |= flag
+		tmpl = tmpl[start+end+1:]
+	}
+	return t, nil
+}
+
+// 设置文本格式的日志行模板，如"{time} {level} {file}:{line} {msg}"，传空字符串恢复按flags组合的格式
+// 可用占位符：time level name goroutine file func line fields msg，模板用到的调用信息会自动采集
+func (l *Logger) SetTemplate(tmpl string) error {
+	var t *lineTemplate
+	if tmpl != "" {
+		var err error
+		if t, err = parseTemplate(tmpl); err != nil {
+			return err
+		}
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.template = t
+	return nil
+}
+
+// 同Logger.SetTemplate，作用于默认日志对象
+func SetTemplate(tmpl string) error {
+	return logger.SetTemplate(tmpl)
+}
+
+// 获取模板用到的输出字段，未设置模板时返回FLAG_NONE
+func (l *Logger) templateFlags() LogFlag {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	if l.template == nil {
+		return FLAG_NONE
+	}
+	return l.template.flags
+}
+
+// 按模板生成一行日志
+func (l *Logger) formatTemplate(t *lineTemplate, log logMsg, color bool) string {
+	var b strings.Builder
+	for _, p := range t.parts {
+		switch p.key {
+		case "":
+			b.WriteString(p.text)
+		case "time":
+			b.WriteString(log.time)
+		case "level":
+			level := l.textLevel(log.level)
+			if color {
+				level = colorize(log.level, level)
+			}
+			b.WriteString(level)
+		case "name":
+			b.WriteString(log.name)
+		case "goroutine":
+			if log.goID > 0 {
+				b.WriteString(strconv.Itoa(log.goID))
+			}
+		case "file":
+			b.WriteString(log.fileName)
+		case "func":
+			b.WriteString(log.funcName)
+		case "line":
+			b.WriteString(strconv.Itoa(log.lineNo))
+		case "fields":
+			b.WriteString(strings.TrimSuffix(formatFields(log.fields), " "))
+		case "msg":
+			b.WriteString(log.msg)
+		}
+	}
+	return b.String()
+}
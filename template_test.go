@@ -0,0 +1,56 @@
+package MyLog
+
+import (
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTemplates(t *testing.T) {
+	at := time.Date(2024, 6, 1, 8, 30, 15, 0, time.UTC)
+	tests := []struct{ tmpl, want string }{
+		{"{time} {level} {file}:{line} {msg}", "2024-06-01 08:30:15 INFO    template_test.go:LINE hello"},
+		{"{level}|{name}|{func}()|{fields}|{msg}", "INFO   |svc|TestTemplates()|k=v|hello"},
+		// 不含任何字段的模板只输出固定文本
+		{"static", "static"},
+		{"{msg}", "hello"},
+	}
+	for _, tt := range tests {
+		// 未开启相关flags时，模板用到的调用信息也会采集
+		l, buf := newTestLogger(t, WithFlags(FLAG_NONE))
+		l.SetClock(func() time.Time { return at })
+		if err := l.SetTemplate(tt.tmpl); err != nil {
+			t.Fatal(err)
+		}
+		_, _, line, _ := runtime.Caller(0)
+		l.Named("svc").With(Fields{"k": "v"}).Info("hello")
+		l.Flush()
+		want := strings.Replace(tt.want, "LINE", strconv.Itoa(line+1), 1)
+		if got := strings.TrimSuffix(buf.String(), "\n"); got != want {
+			t.Errorf("template %q: got %q, want %q", tt.tmpl, got, want)
+		}
+	}
+}
+
+func TestTemplateErrorsAtSetTime(t *testing.T) {
+	l, buf := newTestLogger(t)
+	for _, tmpl := range []string{"{bogus} {msg}", "{level} {msg"} {
+		if err := l.SetTemplate(tmpl); err == nil {
+			t.Errorf("SetTemplate(%q) returned nil error", tmpl)
+		}
+	}
+	// 设置失败时保留原有模板，传空字符串恢复按flags组合的格式
+	l.SetTemplate("{msg}")
+	l.SetTemplate("{bogus}")
+	l.Info("x")
+	// 模板在写入时读取，恢复前先输出
+	l.Flush()
+	l.SetTemplate("")
+	l.Info("y")
+	l.Flush()
+	if got := buf.String(); got != "x\n[INFO   ] y\n" {
+		t.Fatalf("got %q", got)
+	}
+}
@@ -0,0 +1,54 @@
+package MyLog
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// 缓存的时间字符串，同一秒内的日志复用，只整体替换不原地修改
+type cachedTime struct {
+	layout string
+	sec    int64
+	loc    *time.Location
+	text   string
+}
+
+// 按秒缓存格式化结果，避免每条日志都重新解析格式、分配字符串
+type timeCache struct {
+	p atomic.Pointer[cachedTime]
+}
+
+// 格式化时间，格式精确到秒及以上时复用同一秒的结果，含小数秒的格式每次重新格式化
+func (c *timeCache) format(t time.Time, layout string) string {
+	if hasFraction(layout) {
+		return t.Format(layout)
+	}
+	sec := t.Unix()
+	if ct := c.p.Load(); ct != nil && ct.sec == sec && ct.layout == layout && ct.loc == t.Location() {
+		return ct.text
+	}
+	text := t.Format(layout)
+	c.p.Store(&cachedTime{layout: layout, sec: sec, loc: t.Location(), text: text})
+	return text
+}
+
+// 判断时间格式中是否含有小数秒，如.000、,999，规则与time包相同：连续的0或9之后不能紧跟数字（如2006.01.02中的.01不是小数秒）
+func hasFraction(layout string) bool {
+	for i := 0; i+1 < len(layout); i++ {
+		if layout[i] != '.' && layout[i] != ',' {
+			continue
+		}
+		c := layout[i+1]
+		if c != '0' && c != '9' {
+			continue
+		}
+		j := i + 1
+		for j < len(layout) && layout[j] == c {
+			j++
+		}
+		if j == len(layout) || layout[j] < '0' || layout[j] > '9' {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,67 @@
+package MyLog
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTimeCacheAcrossSecond(t *testing.T) {
+	var c timeCache
+	at := time.Date(2024, 6, 1, 8, 30, 15, 900000000, time.UTC)
+	if got := c.format(at, TIME_FORMAT_SECOND); got != "2024-06-01 08:30:15" {
+		t.Fatalf("got %q", got)
+	}
+	// 同一秒内复用，跨秒后重新格式化
+	if got := c.format(at.Add(50*time.Millisecond), TIME_FORMAT_SECOND); got != "2024-06-01 08:30:15" {
+		t.Fatalf("same second got %q", got)
+	}
+	if got := c.format(at.Add(100*time.Millisecond), TIME_FORMAT_SECOND); got != "2024-06-01 08:30:16" {
+		t.Fatalf("next second got %q", got)
+	}
+	// 修改格式或时区后不使用旧的缓存
+	if got := c.format(at, time.RFC3339); got != "2024-06-01T08:30:15Z" {
+		t.Fatalf("layout change got %q", got)
+	}
+	if got := c.format(at.In(time.FixedZone("UTC+8", 8*3600)), time.RFC3339); got != "2024-06-01T16:30:15+08:00" {
+		t.Fatalf("zone change got %q", got)
+	}
+	// 含小数秒的格式每次都重新格式化
+	if got := c.format(at, TIME_FORMAT_MILLI); got != "2024-06-01 08:30:15.900" {
+		t.Fatalf("milli got %q", got)
+	}
+}
+
+func TestHasFraction(t *testing.T) {
+	tests := map[string]bool{
+		TIME_FORMAT_SECOND:           false,
+		TIME_FORMAT_MILLI:            true,
+		TIME_FORMAT_NANO:             true,
+		"15:04:05,999":               true,
+		"2006.01.02 15:04:05":        false,
+		"2006.01.02 15:04:05.000000": true,
+		time.RFC3339:                 false,
+		time.RFC3339Nano:             true,
+	}
+	for layout, want := range tests {
+		if got := hasFraction(layout); got != want {
+			t.Errorf("hasFraction(%q) = %v, want %v", layout, got, want)
+		}
+	}
+}
+
+func BenchmarkTimeFormatCached(b *testing.B) {
+	var c timeCache
+	now := time.Now()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		c.format(now, TIME_FORMAT_SECOND)
+	}
+}
+
+func BenchmarkTimeFormatUncached(b *testing.B) {
+	now := time.Now()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = now.Format(TIME_FORMAT_SECOND)
+	}
+}
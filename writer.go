@@ -0,0 +1,44 @@
+package MyLog
+
+import (
+	"io"
+	"runtime"
+	"strings"
+)
+
+// 按固定等级输出日志的io.Writer
+type levelWriter struct {
+	logger *Logger
+	level  LevelLog
+}
+
+// 获取以指定等级输出日志的io.Writer，可用于标准库log.SetOutput、http.Server.ErrorLog等
+func (l *Logger) Writer(level LevelLog) io.Writer {
+	return &levelWriter{logger: l, level: level}
+}
+
+// 同Logger.Writer，作用于默认日志对象
+func Writer(level LevelLog) io.Writer {
+	return logger.Writer(level)
+}
+
+// 每次写入作为一条日志，去掉末尾换行，调用信息为调用log.Printf、fmt.Fprintf等处
+func (w *levelWriter) Write(p []byte) (int, error) {
+	w.logger.submit(nil, w.level, nil, strings.TrimSuffix(string(p), "\n"), nil, writerCallerPC())
+	return len(p), nil
+}
+
+// 获取调用Write处的pc，跳过标准库log和fmt包内的帧，找不到时返回0改用默认的调用信息
+func writerCallerPC() uintptr {
+	var pcs [8]uintptr
+	// 跳过runtime.Callers、writerCallerPC和Write
+	n := runtime.Callers(3, pcs[:])
+	for _, pc := range pcs[:n] {
+		// 每个pc单独解析，内联时取最内层的函数判断
+		frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+		if !strings.HasPrefix(frame.Function, "log.") && !strings.HasPrefix(frame.Function, "fmt.") {
+			return pc
+		}
+	}
+	return 0
+}
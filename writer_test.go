@@ -0,0 +1,42 @@
+package MyLog
+
+import (
+	"fmt"
+	"log"
+	"runtime"
+	"strconv"
+	"testing"
+)
+
+func TestWriterBridgesStdLog(t *testing.T) {
+	skipIfDebugStripped(t)
+	l, buf := newTestLogger(t)
+	std := log.New(l.Writer(WARNING), "std: ", 0)
+	std.Println("from stdlib")
+	std.Printf("n=%d", 3)
+	l.Writer(DEBUG).Write([]byte("raw\n"))
+	l.Flush()
+	want := "[WARNING] std: from stdlib\n[WARNING] std: n=3\n[DEBUG  ] raw\n"
+	if buf.String() != want {
+		t.Fatalf("got %q", buf.String())
+	}
+}
+
+// 调用信息指向调用标准库log或fmt的位置，而不是这些包内部
+func TestWriterCallerIsCallSite(t *testing.T) {
+	l, buf := newTestLogger(t, WithFlags(FLAG_FILENAME|FLAG_FUNCNAME|FLAG_LINENO))
+	w := l.Writer(WARNING)
+	std := log.New(w, "", 0)
+	_, _, line, _ := runtime.Caller(0)
+	std.Printf("printf")
+	std.Println("println")
+	fmt.Fprintln(w, "fprintln")
+	l.Flush()
+	want := ""
+	for i, msg := range []string{"printf", "println", "fprintln"} {
+		want += "[writer_test.go TestWriterCallerIsCallSite() line" + strconv.Itoa(line+1+i) + "] " + msg + "\n"
+	}
+	if buf.String() != want {
+		t.Fatalf("got %q, want %q", buf.String(), want)
+	}
+}